@@ -1,22 +1,58 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/conductorone/baton-sdk/pkg/field"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+	"github.com/conductorone/baton-sharepoint/pkg/connector"
 	"github.com/spf13/viper"
 )
 
 var (
 	TenantIDField          = field.StringField("azure-tenant-id", field.WithDescription("Azure Tenant ID"), field.WithRequired(true))
 	ClientIDField          = field.StringField("azure-client-id", field.WithDescription("Azure Client ID"), field.WithRequired(true))
-	ClientSecretField      = field.StringField("azure-client-secret", field.WithDescription("Azure Client Secret"), field.WithRequired(true))
-	GraphDomainField       = field.StringField("azure-graph-domain", field.WithDescription("Domain for Microsoft Graph API"), field.WithDefaultValue("graph.microsoft.com"))
+	ClientSecretField      = field.StringField("azure-client-secret", field.WithDescription("Azure Client Secret, used for Microsoft Graph instead of the certificate"), field.WithIsSecret(true))
+	GraphDomainField       = field.StringField("azure-graph-domain", field.WithDescription("Domain for Microsoft Graph API, defaults to the one of the cloud"))
 	SharePointDomainField  = field.StringField("sharepoint-domain", field.WithDescription("Domain of SharePoint"), field.WithRequired(true))
-	CertFilePathField      = field.StringField("pfx-certificate-file", field.WithDescription("Path to PFX certificate file"), field.WithRequired(true))
-	CertPasswordField      = field.StringField("pfx-certificate-password", field.WithDescription("Password of the PFX certificate"), field.WithRequired(true))
+	CertFilePathField      = field.StringField("pfx-certificate-file", field.WithDescription("Path to PFX certificate file"))
+	CertField              = field.StringField("pfx-certificate", field.WithDescription("Base64-encoded PFX certificate"), field.WithIsSecret(true))
+	CertPasswordField      = field.StringField("pfx-certificate-password", field.WithDescription("Password of the PFX certificate, if it's encrypted"), field.WithIsSecret(true))
+	PEMCertFilePathField   = field.StringField("pem-certificate-file", field.WithDescription("Path to PEM certificate file"))
+	PEMKeyFilePathField    = field.StringField("pem-private-key-file", field.WithDescription("Path to the PEM private key (PKCS#8, PKCS#1 or SEC 1) of the PEM certificate"))
 	SyncOrgLinkGroupsField = field.BoolField(
 		"sync-orglink-groups",
 		field.WithDescription("Don't filter groups like 'SharePointHome Org Links', permission 'SharePoint > Sites.FullControl.All' is required"),
 	)
+	SyncSiteAppPermissionsField = field.BoolField(
+		"sync-site-app-permissions",
+		field.WithDescription("Sync the roles granted to apps on each site through Sites.Selected, permission 'Microsoft Graph > Sites.FullControl.All' is required"),
+	)
+	SyncHiddenListsField = field.BoolField(
+		"sync-hidden-lists",
+		field.WithDescription("Also sync hidden lists and document libraries with unique permissions, most of them are used by SharePoint itself"),
+	)
+	ItemScanLibrariesField = field.StringSliceField(
+		"item-scan-libraries",
+		field.WithDescription("Document libraries whose files and folders are scanned for unique permissions, as '<site URL>|<library title>'"),
+	)
+	ItemScanLimitField = field.IntField(
+		"item-scan-limit",
+		field.WithDescription("Maximum number of files and folders scanned per document library"),
+		field.WithDefaultValue(5000),
+	)
+	CloudField = field.StringField(
+		"azure-cloud",
+		field.WithDescription("Microsoft 365 cloud of the tenant: public, usgov (GCC High), usgov-dod (DoD) or china (21Vianet)"),
+		field.WithDefaultValue(client.CloudPublic.Name),
+		field.WithString(func(r *field.StringRuler) {
+			r.In(client.CloudNames())
+		}),
+	)
+	FederatedTokenFileField = field.StringField(
+		"azure-federated-token-file",
+		field.WithDescription("Path to the federated token file projected by Azure workload identity (i.e. the value of $AZURE_FEDERATED_TOKEN_FILE), used instead of a certificate"),
+	)
 )
 
 var (
@@ -24,21 +60,42 @@ var (
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
 	ConfigurationFields = []field.SchemaField{
+		CloudField,
 		TenantIDField,
 		ClientIDField,
 		ClientSecretField,
 		GraphDomainField,
 		SharePointDomainField,
 		CertFilePathField,
+		CertField,
 		CertPasswordField,
+		PEMCertFilePathField,
+		PEMKeyFilePathField,
+		FederatedTokenFileField,
 		SyncOrgLinkGroupsField,
+		SyncSiteAppPermissionsField,
+		SyncHiddenListsField,
+		ItemScanLibrariesField,
+		ItemScanLimitField,
 	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
 	// username and password can be required together, or an access token can be
 	// marked as mutually exclusive from the username password pair.
-	FieldRelationships = []field.SchemaFieldRelationship{}
+	//
+	// The SharePoint REST API only accepts tokens obtained with a
+	// certificate or a federated token, so exactly one of them is needed in
+	// every combination; the client secret, if any, is only used for
+	// Microsoft Graph along a certificate.
+	FieldRelationships = []field.SchemaFieldRelationship{
+		field.FieldsAtLeastOneUsed(CertFilePathField, CertField, PEMCertFilePathField, FederatedTokenFileField),
+		field.FieldsMutuallyExclusive(CertFilePathField, CertField, PEMCertFilePathField, FederatedTokenFileField),
+		field.FieldsRequiredTogether(PEMCertFilePathField, PEMKeyFilePathField),
+		field.FieldsMutuallyExclusive(PEMCertFilePathField, CertPasswordField),
+		field.FieldsMutuallyExclusive(FederatedTokenFileField, CertPasswordField),
+		field.FieldsMutuallyExclusive(FederatedTokenFileField, ClientSecretField),
+	}
 )
 
 // ValidateConfig is run after the configuration is loaded, and should return an
@@ -46,5 +103,15 @@ var (
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	for _, library := range v.GetStringSlice(ItemScanLibrariesField.FieldName) {
+		if _, err := connector.ParseLibraryScan(library); err != nil {
+			return err
+		}
+	}
+
+	if v.GetInt(ItemScanLimitField.FieldName) < 0 {
+		return fmt.Errorf("%s cannot be negative", ItemScanLimitField.FieldName)
+	}
+
 	return nil
 }
@@ -13,8 +13,164 @@ func TestConfigs(t *testing.T) {
 		FieldRelationships...,
 	)
 
+	required := func(extra map[string]string) map[string]string {
+		configs := map[string]string{
+			"azure-tenant-id":          "tenant",
+			"azure-client-id":          "client",
+			"sharepoint-domain":        "contoso",
+			"pfx-certificate-file":     "cert.pfx",
+			"pfx-certificate-password": "password",
+		}
+		for key, value := range extra {
+			configs[key] = value
+		}
+		return configs
+	}
+
 	testCases := []test.TestCase{
-		// Add test cases here.
+		{
+			Configs: required(nil),
+			IsValid: true,
+			Message: "certificate only",
+		},
+		{
+			Configs: required(map[string]string{"azure-client-secret": "secret"}),
+			IsValid: true,
+			Message: "certificate and client secret",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":     "tenant",
+				"azure-client-id":     "client",
+				"azure-client-secret": "secret",
+				"sharepoint-domain":   "contoso",
+			},
+			IsValid: false,
+			Message: "client secret without certificate",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":   "tenant",
+				"azure-client-id":   "client",
+				"sharepoint-domain": "contoso",
+			},
+			IsValid: false,
+			Message: "no credentials",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":      "tenant",
+				"azure-client-id":      "client",
+				"sharepoint-domain":    "contoso",
+				"pfx-certificate-file": "cert.pfx",
+			},
+			IsValid: true,
+			Message: "unencrypted certificate",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":   "tenant",
+				"azure-client-id":   "client",
+				"sharepoint-domain": "contoso",
+				"pfx-certificate":   "MIIK",
+			},
+			IsValid: true,
+			Message: "base64 certificate",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":      "tenant",
+				"azure-client-id":      "client",
+				"sharepoint-domain":    "contoso",
+				"pem-certificate-file": "cert.pem",
+				"pem-private-key-file": "key.pem",
+			},
+			IsValid: true,
+			Message: "PEM certificate and key",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":      "tenant",
+				"azure-client-id":      "client",
+				"sharepoint-domain":    "contoso",
+				"pem-certificate-file": "cert.pem",
+			},
+			IsValid: false,
+			Message: "PEM certificate without key",
+		},
+		{
+			Configs: required(map[string]string{"pfx-certificate": "MIIK"}),
+			IsValid: false,
+			Message: "both PFX file and base64 certificate",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":          "tenant",
+				"azure-client-id":          "client",
+				"sharepoint-domain":        "contoso",
+				"pem-certificate-file":     "cert.pem",
+				"pem-private-key-file":     "key.pem",
+				"pfx-certificate-password": "password",
+			},
+			IsValid: false,
+			Message: "PEM certificate with PFX password",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":            "tenant",
+				"azure-client-id":            "client",
+				"sharepoint-domain":          "contoso",
+				"azure-federated-token-file": "/var/run/secrets/azure/tokens/azure-identity-token",
+			},
+			IsValid: true,
+			Message: "federated token file",
+		},
+		{
+			Configs: required(map[string]string{"azure-federated-token-file": "/var/run/secrets/azure/tokens/azure-identity-token"}),
+			IsValid: false,
+			Message: "both certificate and federated token file",
+		},
+		{
+			Configs: map[string]string{
+				"azure-tenant-id":            "tenant",
+				"azure-client-id":            "client",
+				"azure-client-secret":        "secret",
+				"sharepoint-domain":          "contoso",
+				"azure-federated-token-file": "/var/run/secrets/azure/tokens/azure-identity-token",
+			},
+			IsValid: false,
+			Message: "client secret with federated token file",
+		},
+		{
+			Configs: required(map[string]string{"azure-cloud": "usgov"}),
+			IsValid: true,
+			Message: "US Government cloud",
+		},
+		{
+			Configs: required(map[string]string{"azure-cloud": "mars"}),
+			IsValid: false,
+			Message: "unknown cloud",
+		},
+		{
+			Configs: required(map[string]string{"item-scan-libraries": "https://contoso.sharepoint.com/sites/hr|Confidential"}),
+			IsValid: true,
+			Message: "library to scan",
+		},
+		{
+			Configs: required(map[string]string{"item-scan-libraries": "https://contoso.sharepoint.com/sites/hr"}),
+			IsValid: false,
+			Message: "library to scan without title",
+		},
+		{
+			Configs: required(map[string]string{"item-scan-libraries": "contoso|Confidential"}),
+			IsValid: false,
+			Message: "library to scan without site URL",
+		},
+		{
+			Configs: required(map[string]string{"item-scan-limit": "-1"}),
+			IsValid: false,
+			Message: "negative item scan limit",
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
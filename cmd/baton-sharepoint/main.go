@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/conductorone/baton-sdk/pkg/types"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
 	"github.com/conductorone/baton-sharepoint/pkg/connector"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"github.com/spf13/viper"
@@ -49,24 +52,47 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 		return nil, err
 	}
 
-	certFilePath := v.GetString(CertFilePathField.FieldName)
-	certBytes, err := os.ReadFile(certFilePath)
+	cloud, err := client.CloudByName(v.GetString(CloudField.FieldName))
 	if err != nil {
-		l.Error("error reading certificate file", zap.Error(err), zap.String("certFilePath", certFilePath))
-		return nil, fmt.Errorf("failed to read certificate file: %w", err)
+		return nil, err
+	}
+
+	// a federated token is used instead of a certificate
+	federatedTokenFile := v.GetString(FederatedTokenFileField.FieldName)
+
+	var cert *client.Certificate
+	if federatedTokenFile == "" {
+		cert, err = loadCertificate(v)
+		if err != nil {
+			l.Error("error loading certificate", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	var itemScanLibraries []connector.LibraryScan
+	for _, value := range v.GetStringSlice(ItemScanLibrariesField.FieldName) {
+		library, err := connector.ParseLibraryScan(value)
+		if err != nil {
+			return nil, err
+		}
+		itemScanLibraries = append(itemScanLibraries, library)
 	}
-	certContent := string(certBytes)
 
 	cb, err := connector.New(
 		ctx,
+		cloud,
 		v.GetString(TenantIDField.FieldName),
 		v.GetString(ClientIDField.FieldName),
 		v.GetString(ClientSecretField.FieldName),
 		v.GetString(GraphDomainField.FieldName),
 		v.GetString(SharePointDomainField.FieldName),
-		certContent,
-		v.GetString(CertPasswordField.FieldName),
+		cert,
+		federatedTokenFile,
 		v.GetBool(SyncOrgLinkGroupsField.FieldName),
+		v.GetBool(SyncSiteAppPermissionsField.FieldName),
+		v.GetBool(SyncHiddenListsField.FieldName),
+		itemScanLibraries,
+		v.GetInt(ItemScanLimitField.FieldName),
 	)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
@@ -81,3 +107,37 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 
 	return connector, nil
 }
+
+// loadCertificate loads the certificate from the PFX file, the
+// base64-encoded PFX content or the PEM certificate and key files.
+func loadCertificate(v *viper.Viper) (*client.Certificate, error) {
+	password := v.GetString(CertPasswordField.FieldName)
+
+	if certFilePath := v.GetString(CertFilePathField.FieldName); certFilePath != "" {
+		pfxData, err := os.ReadFile(certFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate file: %w", err)
+		}
+		return client.ParsePFXCertificate(pfxData, password)
+	}
+
+	if encoded := v.GetString(CertField.FieldName); encoded != "" {
+		pfxData, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode base64 certificate: %w", err)
+		}
+		return client.ParsePFXCertificate(pfxData, password)
+	}
+
+	certPEM, err := os.ReadFile(v.GetString(PEMCertFilePathField.FieldName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PEM certificate file: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(v.GetString(PEMKeyFilePathField.FieldName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read PEM private key file: %w", err)
+	}
+
+	return client.ParsePEMCertificate(certPEM, keyPEM)
+}
@@ -0,0 +1,292 @@
+// Package claims parses the login names SharePoint gives to its users and
+// groups, they are encoded claims like `i:0#.f|membership|john@contoso.com`.
+//
+// The encoding is `<identity><reserved><claim type><value type><issuer type>|<issuer>|<value>`
+// where the issuer is only present for forms, trusted and claim provider
+// issuers, e.g. `c:0(.s|true` has no issuer.
+//
+// documentation: https://learn.microsoft.com/en-us/sharepoint/dev/general-development/claims-encoding-in-sharepoint
+package claims
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind tells what sort of identity a claim represents.
+type Kind int
+
+const (
+	// Unknown is a valid claim (or login name) this package doesn't know about.
+	Unknown Kind = iota
+	// User is an Entra user, `i:0#.f|membership|<upn>`.
+	User
+	// WindowsUser is an Active Directory user, `i:0#.w|<domain>\<user>`.
+	WindowsUser
+	// WindowsGroup is an Active Directory security group, `c:0+.w|<sid>`.
+	WindowsGroup
+	// EntraGroup is an Entra security group, `c:0t.c|tenant|<object id>`.
+	EntraGroup
+	// M365Group is the members of a Microsoft 365 group, `c:0o.c|federateddirectoryclaimprovider|<object id>`.
+	M365Group
+	// M365GroupOwners is the owners of a Microsoft 365 group, `c:0o.c|federateddirectoryclaimprovider|<object id>_o`.
+	M365GroupOwners
+	// App is a SharePoint add-in or Entra app, `i:0i.t|ms.sp.ext|<app id>@<tenant id>`.
+	App
+	// RoleManager is a role like "Everyone except external users", `c:0-.f|rolemanager|<role>`.
+	RoleManager
+	// Everyone is everybody in the tenant, guests included, `c:0(.s|true`.
+	Everyone
+	// AllWindowsUsers is every user authenticated by Windows, `c:0!.s|windows`.
+	AllWindowsUsers
+	// System is the SharePoint system account, `SHAREPOINT\system`.
+	System
+	// ExternalUser is a guest SharePoint shared with by email, it isn't in
+	// Entra, `i:0#.f|membership|urn%3aspo%3aguest#<email>`.
+	ExternalUser
+)
+
+func (k Kind) String() string {
+	value := ""
+	switch k {
+	case Unknown:
+		value = "Unknown"
+	case User:
+		value = "User"
+	case WindowsUser:
+		value = "Windows User"
+	case WindowsGroup:
+		value = "Windows Group"
+	case EntraGroup:
+		value = "Entra Group"
+	case M365Group:
+		value = "Microsoft 365 Group"
+	case M365GroupOwners:
+		value = "Microsoft 365 Group Owners"
+	case App:
+		value = "App"
+	case RoleManager:
+		value = "Role"
+	case Everyone:
+		value = "Everyone"
+	case AllWindowsUsers:
+		value = "All Windows Users"
+	case System:
+		value = "System"
+	case ExternalUser:
+		value = "External User"
+	}
+
+	return value
+}
+
+const (
+	// issuers known to this package, compared case insensitive
+	issuerMembership  = "membership"
+	issuerTenant      = "tenant"
+	issuerFederated   = "federateddirectoryclaimprovider"
+	issuerAddIn       = "ms.sp.ext"
+	issuerRoleManager = "rolemanager"
+
+	// suffix SharePoint adds to a Microsoft 365 group ID to mean its owners
+	ownersSuffix = "_o"
+
+	// prefix of the value of external users, followed by their email
+	externalUserPrefix = "urn%3aspo%3aguest#"
+
+	systemAccount = `SHAREPOINT\system`
+)
+
+// ErrMalformed is returned when a login name looks like a claim but cannot be parsed.
+var ErrMalformed = errors.New("malformed claim")
+
+// Identity is a parsed login name.
+type Identity struct {
+	// Raw is the login name as given.
+	Raw string
+	// IsClaim tells if Raw is an encoded claim, the fields below are empty otherwise.
+	IsClaim bool
+	// IsIdentityClaim tells an identity claim (`i:`) apart from any other claim (`c:`).
+	IsIdentityClaim bool
+	ClaimType       byte
+	ValueType       byte
+	IssuerType      byte
+	// Issuer is the name of the original issuer, empty when the issuer type implies it.
+	Issuer string
+	// Value is the claim value as encoded.
+	Value string
+
+	// Kind is the sort of identity.
+	Kind Kind
+	// Identifier is what identifies the principal, e.g. the user principal
+	// name of users, the email of external users or the object ID of groups
+	// (without the owners suffix).
+	Identifier string
+	// TenantID is the tenant of apps, empty for any other kind.
+	TenantID string
+}
+
+// hasIssuer tells if the issuer type is followed by the name of the issuer.
+func hasIssuer(issuerType byte) bool {
+	switch issuerType {
+	case 'f', 't', 'c': // forms, trusted provider and claim provider
+		return true
+	default:
+		return false
+	}
+}
+
+// Parse parses a SharePoint login name. Login names that aren't claims,
+// like `SHAREPOINT\system`, are parsed as well; an error is only returned
+// for claims that cannot be decoded.
+func Parse(loginName string) (Identity, error) {
+	id := Identity{Raw: loginName, Identifier: loginName}
+
+	if !looksLikeClaim(loginName) {
+		if strings.EqualFold(loginName, systemAccount) {
+			id.Kind = System
+		}
+		return id, nil
+	}
+
+	if len(loginName) < 8 || loginName[2] != '0' || loginName[6] != '|' {
+		return Identity{}, fmt.Errorf("%w: '%s'", ErrMalformed, loginName)
+	}
+
+	id.IsClaim = true
+	id.IsIdentityClaim = loginName[0] == 'i'
+	id.ClaimType = loginName[3]
+	id.ValueType = loginName[4]
+	id.IssuerType = loginName[5]
+
+	rest := loginName[7:]
+	if hasIssuer(id.IssuerType) {
+		issuer, value, found := strings.Cut(rest, "|")
+		if !found || issuer == "" {
+			return Identity{}, fmt.Errorf("%w: missing issuer in '%s'", ErrMalformed, loginName)
+		}
+		id.Issuer = issuer
+		rest = value
+	}
+
+	if rest == "" {
+		return Identity{}, fmt.Errorf("%w: missing value in '%s'", ErrMalformed, loginName)
+	}
+	id.Value = rest
+	id.Identifier = rest
+	id.Kind = kindOf(id)
+
+	switch id.Kind {
+	case M365GroupOwners:
+		id.Identifier = strings.TrimSuffix(id.Value, ownersSuffix)
+	case ExternalUser:
+		id.Identifier = id.Value[len(externalUserPrefix):]
+	case App:
+		if appID, tenantID, found := strings.Cut(id.Value, "@"); found {
+			id.Identifier = appID
+			id.TenantID = tenantID
+		}
+	default:
+	}
+
+	return id, nil
+}
+
+func looksLikeClaim(loginName string) bool {
+	return len(loginName) >= 2 && (loginName[0] == 'i' || loginName[0] == 'c') && loginName[1] == ':'
+}
+
+func kindOf(id Identity) Kind {
+	switch {
+	case id.IsIdentityClaim && id.ClaimType == '#' && id.IssuerType == 'f' && strings.EqualFold(id.Issuer, issuerMembership):
+		if len(id.Value) > len(externalUserPrefix) && strings.EqualFold(id.Value[:len(externalUserPrefix)], externalUserPrefix) {
+			return ExternalUser
+		}
+		return User
+	case id.IsIdentityClaim && id.ClaimType == '#' && id.IssuerType == 'w':
+		return WindowsUser
+	case !id.IsIdentityClaim && id.ClaimType == '+' && id.IssuerType == 'w':
+		return WindowsGroup
+	case !id.IsIdentityClaim && id.IssuerType == 'c' && strings.EqualFold(id.Issuer, issuerTenant):
+		return EntraGroup
+	case !id.IsIdentityClaim && id.IssuerType == 'c' && strings.EqualFold(id.Issuer, issuerFederated):
+		if strings.HasSuffix(id.Value, ownersSuffix) {
+			return M365GroupOwners
+		}
+		return M365Group
+	case id.IsIdentityClaim && id.IssuerType == 't' && strings.EqualFold(id.Issuer, issuerAddIn):
+		return App
+	case id.IssuerType == 'f' && strings.EqualFold(id.Issuer, issuerRoleManager):
+		return RoleManager
+	case !id.IsIdentityClaim && id.ClaimType == '(' && id.IssuerType == 's' && id.Value == "true":
+		return Everyone
+	case !id.IsIdentityClaim && id.ClaimType == '!' && id.IssuerType == 's' && strings.EqualFold(id.Value, "windows"):
+		return AllWindowsUsers
+	default:
+		return Unknown
+	}
+}
+
+// String encodes the identity back into a login name.
+func (i Identity) String() string {
+	if !i.IsClaim {
+		return i.Raw
+	}
+
+	var sb strings.Builder
+	if i.IsIdentityClaim {
+		sb.WriteString("i:0")
+	} else {
+		sb.WriteString("c:0")
+	}
+	sb.WriteByte(i.ClaimType)
+	sb.WriteByte(i.ValueType)
+	sb.WriteByte(i.IssuerType)
+	sb.WriteByte('|')
+	if hasIssuer(i.IssuerType) {
+		sb.WriteString(i.Issuer)
+		sb.WriteByte('|')
+	}
+	sb.WriteString(i.Value)
+
+	return sb.String()
+}
+
+// IsEntraGroup tells if the identity is an Entra group, either a security
+// group or a Microsoft 365 group (members or owners).
+func (i Identity) IsEntraGroup() bool {
+	return i.Kind == EntraGroup || i.Kind == M365Group || i.Kind == M365GroupOwners
+}
+
+// Same tells if both identities are the same principal. The members of an
+// Entra group can show up with either their "tenant" or
+// "federateddirectoryclaimprovider" claim, both are the same group.
+func Same(lhs, rhs Identity) bool {
+	if strings.EqualFold(lhs.Raw, rhs.Raw) {
+		return true
+	}
+
+	isGroupMembers := func(k Kind) bool { return k == EntraGroup || k == M365Group }
+	if isGroupMembers(lhs.Kind) && isGroupMembers(rhs.Kind) {
+		return strings.EqualFold(lhs.Identifier, rhs.Identifier)
+	}
+
+	return false
+}
+
+// UserLoginName makes the login name of an Entra user.
+func UserLoginName(userPrincipalName string) string {
+	return "i:0#.f|" + issuerMembership + "|" + userPrincipalName
+}
+
+// EntraGroupLoginName makes the login name of an Entra group.
+func EntraGroupLoginName(objectID string) string {
+	return "c:0t.c|" + issuerTenant + "|" + objectID
+}
+
+// AppLoginName makes the login name of an app principal, like a SharePoint
+// add-in or an Entra app registration.
+func AppLoginName(appID, tenantID string) string {
+	return "i:0i.t|" + issuerAddIn + "|" + appID + "@" + tenantID
+}
@@ -0,0 +1,258 @@
+package claims
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name       string
+		loginName  string
+		kind       Kind
+		issuer     string
+		identifier string
+		tenantID   string
+	}{
+		{
+			name:       "entra user",
+			loginName:  "i:0#.f|membership|john@contoso.com",
+			kind:       User,
+			issuer:     "membership",
+			identifier: "john@contoso.com",
+		},
+		{
+			name:       "entra guest user",
+			loginName:  "i:0#.f|membership|jane_fabrikam.com#ext#@contoso.onmicrosoft.com",
+			kind:       User,
+			issuer:     "membership",
+			identifier: "jane_fabrikam.com#ext#@contoso.onmicrosoft.com",
+		},
+		{
+			name:       "external user",
+			loginName:  "i:0#.f|membership|urn%3aspo%3aguest#jane@fabrikam.com",
+			kind:       ExternalUser,
+			issuer:     "membership",
+			identifier: "jane@fabrikam.com",
+		},
+		{
+			name:       "external user, upper case",
+			loginName:  "i:0#.f|membership|URN%3ASPO%3AGUEST#jane@fabrikam.com",
+			kind:       ExternalUser,
+			issuer:     "membership",
+			identifier: "jane@fabrikam.com",
+		},
+		{
+			name:       "windows user",
+			loginName:  `i:0#.w|contoso\john`,
+			kind:       WindowsUser,
+			identifier: `contoso\john`,
+		},
+		{
+			name:       "windows group",
+			loginName:  "c:0+.w|s-1-5-21-2127521184-1604012920-1887927527-1029",
+			kind:       WindowsGroup,
+			identifier: "s-1-5-21-2127521184-1604012920-1887927527-1029",
+		},
+		{
+			name:       "entra security group",
+			loginName:  "c:0t.c|tenant|7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1",
+			kind:       EntraGroup,
+			issuer:     "tenant",
+			identifier: "7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1",
+		},
+		{
+			name:       "microsoft 365 group members",
+			loginName:  "c:0o.c|federateddirectoryclaimprovider|7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1",
+			kind:       M365Group,
+			issuer:     "federateddirectoryclaimprovider",
+			identifier: "7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1",
+		},
+		{
+			name:       "microsoft 365 group owners",
+			loginName:  "c:0o.c|federateddirectoryclaimprovider|7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1_o",
+			kind:       M365GroupOwners,
+			issuer:     "federateddirectoryclaimprovider",
+			identifier: "7a1f8e35-3b0b-4d6a-bb9c-7e2b5b86a6a1",
+		},
+		{
+			name:       "sharepoint add-in",
+			loginName:  "i:0i.t|ms.sp.ext|0f4ee4ba-7ed3-4c1c-9b5c-0aa8c0a1e2d3@5b1e6d1f-8f7c-4b0a-9f1e-2a3b4c5d6e7f",
+			kind:       App,
+			issuer:     "ms.sp.ext",
+			identifier: "0f4ee4ba-7ed3-4c1c-9b5c-0aa8c0a1e2d3",
+			tenantID:   "5b1e6d1f-8f7c-4b0a-9f1e-2a3b4c5d6e7f",
+		},
+		{
+			name:       "everyone except external users",
+			loginName:  "c:0-.f|rolemanager|spo-grid-all-users/5b1e6d1f-8f7c-4b0a-9f1e-2a3b4c5d6e7f",
+			kind:       RoleManager,
+			issuer:     "rolemanager",
+			identifier: "spo-grid-all-users/5b1e6d1f-8f7c-4b0a-9f1e-2a3b4c5d6e7f",
+		},
+		{
+			name:       "everyone",
+			loginName:  "c:0(.s|true",
+			kind:       Everyone,
+			identifier: "true",
+		},
+		{
+			name:       "all windows users",
+			loginName:  "c:0!.s|windows",
+			kind:       AllWindowsUsers,
+			identifier: "windows",
+		},
+		{
+			name:       "system account",
+			loginName:  `SHAREPOINT\system`,
+			kind:       System,
+			identifier: `SHAREPOINT\system`,
+		},
+		{
+			name:       "unknown claim",
+			loginName:  "c:0e.t|adfs|john@contoso.com",
+			kind:       Unknown,
+			issuer:     "adfs",
+			identifier: "john@contoso.com",
+		},
+		{
+			name:       "not a claim",
+			loginName:  `contoso\john`,
+			kind:       Unknown,
+			identifier: `contoso\john`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := Parse(tc.loginName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if id.Kind != tc.kind {
+				t.Errorf("kind: got %s, expected %s", id.Kind, tc.kind)
+			}
+			if id.Issuer != tc.issuer {
+				t.Errorf("issuer: got '%s', expected '%s'", id.Issuer, tc.issuer)
+			}
+			if id.Identifier != tc.identifier {
+				t.Errorf("identifier: got '%s', expected '%s'", id.Identifier, tc.identifier)
+			}
+			if id.TenantID != tc.tenantID {
+				t.Errorf("tenant ID: got '%s', expected '%s'", id.TenantID, tc.tenantID)
+			}
+			if id.String() != tc.loginName {
+				t.Errorf("encoding: got '%s', expected '%s'", id.String(), tc.loginName)
+			}
+		})
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	testCases := []string{
+		"i:",
+		"i:0#.f",
+		"i:0#.f|",
+		"i:1#.f|membership|john@contoso.com",
+		"i:0#.f|membership",
+		"i:0#.f||john@contoso.com",
+		"c:0t.c|tenant|",
+		"c:0(.s|",
+		"c:0(.s-true",
+	}
+
+	for _, loginName := range testCases {
+		t.Run(loginName, func(t *testing.T) {
+			_, err := Parse(loginName)
+			if !errors.Is(err, ErrMalformed) {
+				t.Errorf("expected ErrMalformed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSame(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lhs      string
+		rhs      string
+		expected bool
+	}{
+		{"same user different case", "i:0#.f|membership|John@contoso.com", "i:0#.f|membership|john@contoso.com", true},
+		{"different users", "i:0#.f|membership|john@contoso.com", "i:0#.f|membership|jane@contoso.com", false},
+		{"tenant and federated claims of a group", "c:0t.c|tenant|abc", "c:0o.c|federateddirectoryclaimprovider|abc", true},
+		{"owners are not members", "c:0o.c|federateddirectoryclaimprovider|abc_o", "c:0o.c|federateddirectoryclaimprovider|abc", false},
+		{"owners are not the security group", "c:0o.c|federateddirectoryclaimprovider|abc_o", "c:0t.c|tenant|abc", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lhs, err := Parse(tc.lhs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rhs, err := Parse(tc.rhs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := Same(lhs, rhs); got != tc.expected {
+				t.Errorf("got %v, expected %v", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestLoginNames(t *testing.T) {
+	user, err := Parse(UserLoginName("john@contoso.com"))
+	if err != nil || user.Kind != User || user.Identifier != "john@contoso.com" {
+		t.Errorf("unexpected user identity %+v, error: %v", user, err)
+	}
+
+	group, err := Parse(EntraGroupLoginName("abc"))
+	if err != nil || group.Kind != EntraGroup || group.Identifier != "abc" {
+		t.Errorf("unexpected group identity %+v, error: %v", group, err)
+	}
+
+	app, err := Parse(AppLoginName("app", "tenant"))
+	if err != nil || app.Kind != App || app.Identifier != "app" || app.TenantID != "tenant" {
+		t.Errorf("unexpected app identity %+v, error: %v", app, err)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("i:0#.f|membership|john@contoso.com")
+	f.Add("c:0o.c|federateddirectoryclaimprovider|abc_o")
+	f.Add("i:0i.t|ms.sp.ext|app@tenant")
+	f.Add("c:0(.s|true")
+	f.Add(`i:0#.w|contoso\john`)
+	f.Add("i:0#.f|membership|urn%3aspo%3aguest#jane@fabrikam.com")
+	f.Add(`SHAREPOINT\system`)
+	f.Add("c:0-.f|rolemanager|spo-grid-all-users/tenant")
+
+	f.Fuzz(func(t *testing.T, loginName string) {
+		id, err := Parse(loginName)
+		if err != nil {
+			if !errors.Is(err, ErrMalformed) {
+				t.Fatalf("unexpected error type: %v", err)
+			}
+			return
+		}
+
+		if id.String() != loginName {
+			t.Fatalf("round trip failed: got '%s', expected '%s'", id.String(), loginName)
+		}
+
+		again, err := Parse(id.String())
+		if err != nil {
+			t.Fatalf("cannot parse encoded identity '%s', error: %v", id.String(), err)
+		}
+		if again != id {
+			t.Fatalf("parsing is not stable: got %+v, expected %+v", again, id)
+		}
+		if !Same(id, again) {
+			t.Fatalf("identity is not the same as itself: %+v", id)
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package client
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // x5t is the SHA-1 thumbprint of the certificate, it's not used for security
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/golang-jwt/jwt/v5"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// Certificate is the certificate (and its chain) and private key the app
+// authenticates with.
+type Certificate struct {
+	// Chain starts with the certificate of the key.
+	Chain []*x509.Certificate
+	Key   crypto.PrivateKey
+}
+
+// ParsePFXCertificate parses the raw content of a PFX (PKCS#12) file,
+// password may be empty for unencrypted files.
+func ParsePFXCertificate(pfxData []byte, password string) (*Certificate, error) {
+	key, cert, caCerts, err := pkcs12.DecodeChain(pfxData, password)
+	if err != nil {
+		if password == "" {
+			return nil, fmt.Errorf("failed to decode .pfx certificate without password, error: %w", err)
+		}
+		return nil, fmt.Errorf("failed to decrypt .pfx certificate with password, error: %w", err)
+	}
+
+	return newCertificate(append([]*x509.Certificate{cert}, caCerts...), key)
+}
+
+// ParsePEMCertificate parses a PEM certificate (and its chain) and a PEM
+// private key, either PKCS#8, PKCS#1 (RSA) or SEC 1 (ECDSA).
+func ParsePEMCertificate(certPEM, keyPEM []byte) (*Certificate, error) {
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(certPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM certificate, error: %w", err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("no certificate found in PEM data")
+	}
+
+	var key crypto.PrivateKey
+	for block, rest := pem.Decode(keyPEM); block != nil && key == nil; block, rest = pem.Decode(rest) {
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			return nil, errors.New("encrypted PEM private keys are not supported, decrypt the key or use a PFX certificate")
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse PEM private key, error: %w", err)
+		}
+	}
+	if key == nil {
+		return nil, errors.New("no private key found in PEM data")
+	}
+
+	return newCertificate(chain, key)
+}
+
+// newCertificate checks the key is supported and puts the certificate
+// matching the key first in the chain.
+func newCertificate(chain []*x509.Certificate, key crypto.PrivateKey) (*Certificate, error) {
+	var public crypto.PublicKey
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		public = k.Public()
+	case *ecdsa.PrivateKey:
+		public = k.Public()
+	default:
+		return nil, fmt.Errorf("key of type %T is not supported, only RSA and ECDSA keys are", key)
+	}
+
+	for i, cert := range chain {
+		if cert == nil {
+			continue
+		}
+
+		if k, ok := public.(interface{ Equal(crypto.PublicKey) bool }); ok && k.Equal(cert.PublicKey) {
+			chain[0], chain[i] = chain[i], chain[0]
+			return &Certificate{Chain: chain, Key: key}, nil
+		}
+	}
+
+	return nil, errors.New("private key doesn't match any certificate")
+}
+
+// credential makes the token credential of the certificate. azidentity only
+// signs client assertions with RSA keys, so the assertions of ECDSA keys
+// are signed here.
+func (c *Certificate) credential(tenantID, clientID string, options azcore.ClientOptions) (azcore.TokenCredential, error) {
+	if _, ok := c.Key.(*ecdsa.PrivateKey); !ok {
+		return azidentity.NewClientCertificateCredential(tenantID, clientID, c.Chain, c.Key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions:        options,
+			SendCertificateChain: true,
+		})
+	}
+
+	return azidentity.NewClientAssertionCredential(tenantID, clientID, func(ctx context.Context) (string, error) {
+		return c.clientAssertion(options.Cloud.ActiveDirectoryAuthorityHost, tenantID, clientID)
+	}, &azidentity.ClientAssertionCredentialOptions{
+		ClientOptions: options,
+	})
+}
+
+// clientAssertion signs a client assertion with an ECDSA key, for the
+// token endpoint of the tenant on authorityHost.
+//
+// documentation: https://learn.microsoft.com/en-us/entra/identity-platform/certificate-credentials
+func (c *Certificate) clientAssertion(authorityHost, tenantID, clientID string) (string, error) {
+	key, ok := c.Key.(*ecdsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("key of type %T cannot sign client assertions", c.Key)
+	}
+
+	var method jwt.SigningMethod
+	switch key.Curve {
+	case elliptic.P256():
+		method = jwt.SigningMethodES256
+	case elliptic.P384():
+		method = jwt.SigningMethodES384
+	case elliptic.P521():
+		method = jwt.SigningMethodES512
+	default:
+		return "", fmt.Errorf("curve '%s' is not supported", key.Curve.Params().Name)
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{fmt.Sprintf("%s/%s/oauth2/v2.0/token", strings.TrimSuffix(authorityHost, "/"), tenantID)},
+		Issuer:    clientID,
+		Subject:   clientID,
+		ID:        hex.EncodeToString(jti),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(10 * time.Minute)),
+	})
+
+	sha1Thumbprint := sha1.Sum(c.Chain[0].Raw) //nolint:gosec // see import
+	sha256Thumbprint := sha256.Sum256(c.Chain[0].Raw)
+	token.Header["x5t"] = base64.RawURLEncoding.EncodeToString(sha1Thumbprint[:])
+	token.Header["x5t#S256"] = base64.RawURLEncoding.EncodeToString(sha256Thumbprint[:])
+
+	return token.SignedString(key)
+}
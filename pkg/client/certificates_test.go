@@ -0,0 +1,192 @@
+package client
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func selfSignedCertificate(t *testing.T, key crypto.Signer) *x509.Certificate {
+	t.Helper()
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "baton-sharepoint"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+func TestParseCertificates(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaCert := selfSignedCertificate(t, rsaKey)
+	ecCert := selfSignedCertificate(t, ecKey)
+
+	rsaCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: rsaCert.Raw})
+	ecCertPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ecCert.Raw})
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := pkcs12.Modern.Encode(rsaKey, rsaCert, nil, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unencrypted, err := pkcs12.Passwordless.Encode(ecKey, ecCert, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name    string
+		parse   func() (*Certificate, error)
+		want    *x509.Certificate
+		wantErr bool
+	}{
+		{
+			name:  "encrypted PFX",
+			parse: func() (*Certificate, error) { return ParsePFXCertificate(encrypted, "password") },
+			want:  rsaCert,
+		},
+		{
+			name:    "encrypted PFX with wrong password",
+			parse:   func() (*Certificate, error) { return ParsePFXCertificate(encrypted, "wrong") },
+			wantErr: true,
+		},
+		{
+			name:  "unencrypted PFX",
+			parse: func() (*Certificate, error) { return ParsePFXCertificate(unencrypted, "") },
+			want:  ecCert,
+		},
+		{
+			name: "PEM with PKCS#1 key",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(rsaCertPEM, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+			},
+			want: rsaCert,
+		},
+		{
+			name: "PEM with PKCS#8 key",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(ecCertPEM, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+			},
+			want: ecCert,
+		},
+		{
+			name: "PEM with SEC 1 key",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(ecCertPEM, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}))
+			},
+			want: ecCert,
+		},
+		{
+			name: "PEM with chain, the certificate of the key comes first",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(append(rsaCertPEM, ecCertPEM...), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+			},
+			want: ecCert,
+		},
+		{
+			name: "PEM with key of another certificate",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(rsaCertPEM, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "PEM without key",
+			parse: func() (*Certificate, error) {
+				return ParsePEMCertificate(rsaCertPEM, rsaCertPEM)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.parse()
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Chain[0].Equal(tc.want) {
+				t.Errorf("got certificate %q first, want %q", got.Chain[0].Subject, tc.want.Subject)
+			}
+		})
+	}
+}
+
+func TestClientAssertion(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert := &Certificate{Chain: []*x509.Certificate{selfSignedCertificate(t, key)}, Key: key}
+
+	assertion, err := cert.clientAssertion(CloudUSGov.AuthorityHost, "tenant", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := &jwt.RegisteredClaims{}
+	token, err := jwt.ParseWithClaims(assertion, claims, func(token *jwt.Token) (any, error) {
+		return key.Public(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodES384.Alg()}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if claims.Issuer != "client" || claims.Subject != "client" {
+		t.Errorf("got issuer %q and subject %q, want the client ID", claims.Issuer, claims.Subject)
+	}
+	if want := "https://login.microsoftonline.us/tenant/oauth2/v2.0/token"; len(claims.Audience) != 1 || claims.Audience[0] != want {
+		t.Errorf("got audience %v, want %q", claims.Audience, want)
+	}
+	if _, ok := token.Header["x5t#S256"]; !ok {
+		t.Error("missing x5t#S256 header")
+	}
+}
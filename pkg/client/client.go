@@ -2,17 +2,18 @@ package client
 
 import (
 	"context"
-	"crypto/rsa"
-	"crypto/x509"
 	"fmt"
+	"net/http"
 	"net/url"
 	"path"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
-	"software.sslmate.com/src/go-pkcs12"
 
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/conductorone/baton-sharepoint/pkg/errorexplained"
@@ -23,7 +24,10 @@ import (
 const (
 	apiVersion              = "v1.0"
 	betaVersion             = "beta"
-	scopeSharePointTemplate = "https://%s.sharepoint.com/.default"
+	scopeSharePointTemplate = "https://%s/.default"
+
+	// tokens are renewed when they expire in less than that
+	tokenExpiryMargin = 5 * time.Minute
 )
 
 // makeGraphReadScopes is a helper function that generates a default graph scope.
@@ -42,11 +46,24 @@ type Client struct {
 	certbasedToken azcore.TokenCredential
 	http           *uhttp.BaseHttpClient
 
+	// Microsoft 365 cloud of the tenant
+	cloud Cloud
+
+	// SharePoint tokens by host; in multi-geo tenants every geo location
+	// has its own host (i.e. contoso-eur.sharepoint.com) and tokens are
+	// only valid for the host they were requested for.
+	sharePointTokens sync.Map
+
 	// SharePoint related stuff
 	tenantID         string
 	clientID         string
 	sharePointDomain string
 
+	// URL of the sites seen so far, keyed by their Microsoft Graph ID
+	siteWebURLs sync.Map
+	// URL of the webs (subsites) seen so far, keyed by their ID
+	webURLs sync.Map
+
 	// SharePointHome OrgLinks groups related stuff
 	//
 	// if this is set to true, the costumer needs to grant the permission
@@ -55,6 +72,11 @@ type Client struct {
 	dontFilterSharePointSpecialGroups bool
 }
 
+// TenantID returns the ID of the Entra tenant the client works on.
+func (c *Client) TenantID() string {
+	return c.tenantID
+}
+
 type QueryOption func(*queryOptions)
 
 type queryOptions struct {
@@ -67,6 +89,58 @@ func WithoutEventualConsistency() QueryOption {
 	}
 }
 
+// sharePointHost returns the SharePoint host of rawURL, the host of the
+// tenant's default geo location is used for URLs without host.
+func (c *Client) sharePointHost(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Sprintf("%s.%s", c.sharePointDomain, c.cloud.SharePointSuffix), nil
+	}
+
+	// never hand out tokens to hosts that are not SharePoint's
+	if !strings.HasSuffix(host, "."+c.cloud.SharePointSuffix) {
+		return "", fmt.Errorf("'%s' is not a SharePoint host of the %s cloud", host, c.cloud.Name)
+	}
+
+	return host, nil
+}
+
+// sharePointScopes returns the scopes of SharePoint REST API tokens for host.
+func sharePointScopes(host string) []string {
+	return []string{fmt.Sprintf(scopeSharePointTemplate, host)}
+}
+
+// sharePointToken returns a SharePoint REST API token valid for the host of
+// rawURL, tokens are cached per host.
+func (c *Client) sharePointToken(ctx context.Context, rawURL string) (string, error) {
+	host, err := c.sharePointHost(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	if cached, ok := c.sharePointTokens.Load(host); ok {
+		token := cached.(azcore.AccessToken)
+		if time.Until(token.ExpiresOn) > tokenExpiryMargin {
+			return token.Token, nil
+		}
+	}
+
+	token, err := c.certbasedToken.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: sharePointScopes(host),
+	})
+	if err != nil {
+		return "", err
+	}
+	c.sharePointTokens.Store(host, token)
+
+	return token.Token, nil
+}
+
 func (c *Client) buildURL(reqPath string, v url.Values) string {
 	ux := url.URL{
 		Scheme:   "https",
@@ -132,61 +206,95 @@ func (c *Client) query(ctx context.Context, scopes []string, method, requestURL
 	return nil
 }
 
+// newFederatedTokenCredential makes a credential out of the federated
+// token file projected by Azure workload identity, the file is read again
+// when the token in it is rotated.
+//
+// documentation: https://learn.microsoft.com/en-us/entra/workload-id/workload-identity-federation
+func newFederatedTokenCredential(tenantID, clientID, tokenFilePath string, options *azidentity.WorkloadIdentityCredentialOptions) (azcore.TokenCredential, error) {
+	options.TenantID = tenantID
+	options.ClientID = clientID
+	options.TokenFilePath = tokenFilePath
+
+	return azidentity.NewWorkloadIdentityCredential(options)
+}
+
+// Option changes how New makes the client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient *http.Client
+}
+
+// WithHTTPClient makes the client send its requests, to Entra, Microsoft
+// Graph and SharePoint, with httpClient instead of its own.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = httpClient
+	}
+}
+
 // New creates a new SharePoint client.
-// pfxCert should be the raw content of a PFX certificate file.
-func New(ctx context.Context, tenantID, clientID, clientSecret, graphDomain, sharepointDomain, pfxCert, pfxCertPassword string, syncSharePointHomeOrgLinks bool) (*Client, error) {
-	uhttpOptions := []uhttp.Option{
-		uhttp.WithLogger(true, ctxzap.Extract(ctx)),
-	}
-	httpClient, err := uhttp.NewClient(
-		ctx,
-		uhttpOptions...,
-	)
-	if err != nil {
-		return nil, err
+// The certificate authenticates the SharePoint REST API and, when clientSecret
+// is empty, Microsoft Graph too. When federatedTokenFile is given, client
+// assertions are read from it instead (Azure workload identity) and
+// certificate may be nil.
+// graphDomain defaults to the Microsoft Graph host of the cloud.
+func New(ctx context.Context, cloud Cloud, tenantID, clientID, clientSecret, graphDomain, sharepointDomain string, certificate *Certificate, federatedTokenFile string, syncSharePointHomeOrgLinks bool, opts ...Option) (*Client, error) {
+	cOpts := &clientOptions{}
+	for _, opt := range opts {
+		opt(cOpts)
+	}
+
+	httpClient := cOpts.httpClient
+	if httpClient == nil {
+		uhttpOptions := []uhttp.Option{
+			uhttp.WithLogger(true, ctxzap.Extract(ctx)),
+		}
+		var err error
+		httpClient, err = uhttp.NewClient(
+			ctx,
+			uhttpOptions...,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	options := azcore.ClientOptions{
+		Cloud:     cloud.configuration(),
 		Transport: httpClient,
 	}
 
-	// we cannot use errorexplained package with `cred`, azidentity has full control of the authentication flow
-	cred, err := azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{
-		ClientOptions: options,
-	})
-	if err != nil {
-		return nil, err
+	if graphDomain == "" {
+		graphDomain = cloud.GraphDomain
 	}
 
-	// Use the raw content as is since it was loaded from a file
-	pfxData := []byte(pfxCert)
-
-	privkey, cert, err := pkcs12.Decode(pfxData, pfxCertPassword)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decrypt .pfx certificate with password, error: %w", err)
-	}
-
-	rsaKey, ok := privkey.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("key is not RSA")
-	}
-
-	certcred, err := azidentity.NewClientCertificateCredential(
-		tenantID,
-		clientID,
-		[]*x509.Certificate{cert},
-		rsaKey,
-		&azidentity.ClientCertificateCredentialOptions{
-			ClientOptions: azcore.ClientOptions{
-				Transport: httpClient,
-			},
-			SendCertificateChain: true,
-		},
-	)
+	var certcred azcore.TokenCredential
+	var err error
+	if federatedTokenFile != "" {
+		certcred, err = newFederatedTokenCredential(tenantID, clientID, federatedTokenFile, &azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: options,
+		})
+	} else {
+		certcred, err = certificate.credential(tenantID, clientID, options)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	// the certificate (or federated token) serves Microsoft Graph too, unless a client secret is given
+	var cred azcore.TokenCredential = certcred
+	if clientSecret != "" {
+		// we cannot use errorexplained package with `cred`, azidentity has full control of the authentication flow
+		cred, err = azidentity.NewClientSecretCredential(tenantID, clientID, clientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: options,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	http, err := uhttp.NewBaseHttpClientWithContext(ctx, httpClient)
 	if err != nil {
 		return nil, err
@@ -197,6 +305,7 @@ func New(ctx context.Context, tenantID, clientID, clientSecret, graphDomain, sha
 		certbasedToken:                    certcred,
 		http:                              http,
 		GraphDomain:                       graphDomain,
+		cloud:                             cloud,
 		tenantID:                          tenantID,
 		clientID:                          clientID,
 		sharePointDomain:                  sharepointDomain,
@@ -17,6 +17,38 @@ type ListUsersResponse struct {
 	Value []SecurityPrincipal `json:"value"`
 }
 
+type ListRoleDefinitionsResponse struct {
+	Value []RoleDefinition `json:"value"`
+}
+
+type ListRoleAssignmentsResponse struct {
+	Value []RoleAssignment `json:"value"`
+}
+
+type ListSitePermissionsResponse struct {
+	Value    []SitePermission `json:"value"`
+	NextLink string           `json:"@odata.nextLink"`
+}
+
+type ListWebsResponse struct {
+	Value    []Web  `json:"value"`
+	NextLink string `json:"odata.nextLink"`
+}
+
+type ListListsResponse struct {
+	Value    []List `json:"value"`
+	NextLink string `json:"odata.nextLink"`
+}
+
+type ListListItemsResponse struct {
+	Value    []ListItem `json:"value"`
+	NextLink string     `json:"odata.nextLink"`
+}
+
+type ListDriveItemPermissionsResponse struct {
+	Value []DrivePermission `json:"value"`
+}
+
 // Local Variables:
 // go-tag-args: ("-transform" "camelcase")
 // End:
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// newTokenEndpoint starts a stand-in for the Microsoft Entra token
+// endpoint, it hands out a token named after the requested scope as long
+// as the client assertion is the expected one.
+func newTokenEndpoint(t *testing.T, tenantID, assertion string) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var (
+		mtx    sync.Mutex
+		scopes []string
+	)
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.URL.Path {
+		case fmt.Sprintf("/%s/v2.0/.well-known/openid-configuration", tenantID):
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"authorization_endpoint": fmt.Sprintf("%s/%s/oauth2/v2.0/authorize", server.URL, tenantID),
+				"token_endpoint":         fmt.Sprintf("%s/%s/oauth2/v2.0/token", server.URL, tenantID),
+				"issuer":                 fmt.Sprintf("%s/%s/v2.0", server.URL, tenantID),
+			})
+		case fmt.Sprintf("/%s/oauth2/v2.0/token", tenantID):
+			if err := r.ParseForm(); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.PostForm.Get("client_assertion") != assertion {
+				w.WriteHeader(http.StatusUnauthorized)
+				_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+				return
+			}
+
+			scope := r.PostForm.Get("scope")
+			mtx.Lock()
+			scopes = append(scopes, scope)
+			mtx.Unlock()
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"access_token": "token for " + scope,
+				"token_type":   "Bearer",
+				"expires_in":   3600,
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &scopes
+}
+
+func TestFederatedTokenCredential(t *testing.T) {
+	const (
+		tenantID = "00000000-0000-0000-0000-000000000001"
+		clientID = "00000000-0000-0000-0000-000000000002"
+		token    = "federated-token"
+	)
+
+	tokenFile := filepath.Join(t.TempDir(), "azure-identity-token")
+	if err := os.WriteFile(tokenFile, []byte(token), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server, requested := newTokenEndpoint(t, tenantID, token)
+
+	cred, err := newFederatedTokenCredential(tenantID, clientID, tokenFile, &azidentity.WorkloadIdentityCredentialOptions{
+		ClientOptions: azcore.ClientOptions{
+			Cloud:     cloud.Configuration{ActiveDirectoryAuthorityHost: server.URL},
+			Transport: server.Client(),
+		},
+		DisableInstanceDiscovery: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		scopes []string
+	}{
+		{
+			name:   "Microsoft Graph",
+			scopes: makeGraphReadScopes(""),
+		},
+		{
+			name:   "SharePoint",
+			scopes: sharePointScopes("contoso.sharepoint.com"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cred.GetToken(context.Background(), policy.TokenRequestOptions{Scopes: tc.scopes})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// MSAL sends the OpenID scopes along the requested one
+			if want := "token for " + tc.scopes[0]; !strings.HasPrefix(got.Token, want) {
+				t.Errorf("got token %q, want %q", got.Token, want)
+			}
+		})
+	}
+
+	if len(*requested) != len(testCases) {
+		t.Errorf("got %d token requests, want %d", len(*requested), len(testCases))
+	}
+}
+
+func TestCloudEndpoints(t *testing.T) {
+	testCases := []struct {
+		cloud         string
+		graphURL      string
+		sharePointURL string
+	}{
+		{
+			cloud:         "",
+			graphURL:      "https://graph.microsoft.com/v1.0/sites",
+			sharePointURL: "https://contoso.sharepoint.com/.default",
+		},
+		{
+			cloud:         "usgov",
+			graphURL:      "https://graph.microsoft.us/v1.0/sites",
+			sharePointURL: "https://contoso.sharepoint.us/.default",
+		},
+		{
+			cloud:         "usgov-dod",
+			graphURL:      "https://dod-graph.microsoft.us/v1.0/sites",
+			sharePointURL: "https://contoso.sharepoint-mil.us/.default",
+		},
+		{
+			cloud:         "china",
+			graphURL:      "https://microsoftgraph.chinacloudapi.cn/v1.0/sites",
+			sharePointURL: "https://contoso.sharepoint.cn/.default",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.cloud, func(t *testing.T) {
+			cloud, err := CloudByName(tc.cloud)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Client{GraphDomain: cloud.GraphDomain, sharePointDomain: "contoso", cloud: cloud}
+
+			if got := c.buildURL("sites", nil); got != tc.graphURL {
+				t.Errorf("got Microsoft Graph URL %q, want %q", got, tc.graphURL)
+			}
+			host, err := c.sharePointHost("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := sharePointScopes(host); len(got) != 1 || got[0] != tc.sharePointURL {
+				t.Errorf("got SharePoint scopes %v, want %q", got, tc.sharePointURL)
+			}
+		})
+	}
+
+	if _, err := CloudByName("mars"); err == nil {
+		t.Error("expected an error for an unknown cloud")
+	}
+}
+
+// countingCredential hands out a token named after the requested scope and
+// counts the requests.
+type countingCredential struct {
+	requests map[string]int
+}
+
+func (c *countingCredential) GetToken(_ context.Context, options policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	c.requests[options.Scopes[0]]++
+	return azcore.AccessToken{Token: "token for " + options.Scopes[0], ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestSharePointTokenPerGeo(t *testing.T) {
+	cred := &countingCredential{requests: map[string]int{}}
+	c := &Client{certbasedToken: cred, sharePointDomain: "contoso", cloud: CloudPublic}
+
+	testCases := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "https://contoso.sharepoint.com/sites/hr", want: "token for https://contoso.sharepoint.com/.default"},
+		{url: "https://contoso-eur.sharepoint.com/sites/hr-eu", want: "token for https://contoso-eur.sharepoint.com/.default"},
+		{url: "https://Contoso-EUR.sharepoint.com/sites/finance-eu", want: "token for https://contoso-eur.sharepoint.com/.default"},
+		{url: "https://contoso.sharepoint.com/sites/finance", want: "token for https://contoso.sharepoint.com/.default"},
+		{url: "https://contoso.example.com/sites/hr", wantErr: true},
+		{url: "https://contoso.sharepoint.us/sites/hr", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := c.sharePointToken(context.Background(), tc.url)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.url)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.url, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.url, got, tc.want)
+		}
+	}
+
+	// one request per host
+	for scope, count := range cred.requests {
+		if count != 1 {
+			t.Errorf("got %d token requests for %s, want 1", count, scope)
+		}
+	}
+	if len(cred.requests) != 2 {
+		t.Errorf("got token requests for %d hosts, want 2", len(cred.requests))
+	}
+}
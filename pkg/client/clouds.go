@@ -0,0 +1,89 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// Cloud is a Microsoft 365 cloud (national clouds are isolated instances of
+// Microsoft Entra, Microsoft Graph and SharePoint with their own hosts).
+//
+// documentation: https://learn.microsoft.com/en-us/graph/deployments
+type Cloud struct {
+	Name string
+	// AuthorityHost is the host tokens are requested from.
+	AuthorityHost string
+	// GraphDomain is the host of Microsoft Graph.
+	GraphDomain string
+	// SharePointSuffix is the domain of the SharePoint hosts, i.e.
+	// `contoso.<suffix>`.
+	SharePointSuffix string
+}
+
+var (
+	CloudPublic = Cloud{
+		Name:             "public",
+		AuthorityHost:    "https://login.microsoftonline.com/",
+		GraphDomain:      "graph.microsoft.com",
+		SharePointSuffix: "sharepoint.com",
+	}
+	// CloudUSGov is the US Government L4 cloud (GCC High).
+	CloudUSGov = Cloud{
+		Name:             "usgov",
+		AuthorityHost:    "https://login.microsoftonline.us/",
+		GraphDomain:      "graph.microsoft.us",
+		SharePointSuffix: "sharepoint.us",
+	}
+	// CloudUSGovDoD is the US Government L5 cloud (DoD).
+	CloudUSGovDoD = Cloud{
+		Name:             "usgov-dod",
+		AuthorityHost:    "https://login.microsoftonline.us/",
+		GraphDomain:      "dod-graph.microsoft.us",
+		SharePointSuffix: "sharepoint-mil.us",
+	}
+	// CloudChina is the cloud operated by 21Vianet.
+	CloudChina = Cloud{
+		Name:             "china",
+		AuthorityHost:    "https://login.chinacloudapi.cn/",
+		GraphDomain:      "microsoftgraph.chinacloudapi.cn",
+		SharePointSuffix: "sharepoint.cn",
+	}
+
+	clouds = []Cloud{CloudPublic, CloudUSGov, CloudUSGovDoD, CloudChina}
+)
+
+// CloudNames returns the names of the supported clouds.
+func CloudNames() []string {
+	ret := make([]string, 0, len(clouds))
+	for _, c := range clouds {
+		ret = append(ret, c.Name)
+	}
+
+	return ret
+}
+
+// CloudByName finds a cloud by its name, the public cloud is returned for
+// an empty name.
+func CloudByName(name string) (Cloud, error) {
+	if name == "" {
+		return CloudPublic, nil
+	}
+
+	for _, c := range clouds {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+
+	return Cloud{}, fmt.Errorf("unknown cloud '%s'", name)
+}
+
+// configuration returns the azidentity configuration of the cloud, only
+// the authority host matters to get tokens.
+func (c Cloud) configuration() cloud.Configuration {
+	return cloud.Configuration{
+		ActiveDirectoryAuthorityHost: c.AuthorityHost,
+		Services:                     map[cloud.ServiceName]cloud.ServiceConfiguration{},
+	}
+}
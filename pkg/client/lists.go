@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ListLists list the lists and document libraries of a site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#listcollection-resource
+func (c *Client) ListLists(ctx context.Context, siteWebURL string) ([]List, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web/lists")
+	query := url.Query()
+	query.Set("$select", "Id,Title,HasUniqueRoleAssignments,Hidden,BaseType")
+	url.RawQuery = query.Encode()
+
+	var ret []List
+	for {
+		var data ListListsResponse
+		_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+		if err != nil {
+			return nil, fmt.Errorf("Client.ListLists: %w", err)
+		}
+		ret = append(ret, data.Value...)
+
+		if data.NextLink == "" {
+			return ret, nil
+		}
+
+		url, err = url.Parse(data.NextLink)
+		if err != nil {
+			return nil, fmt.Errorf("Client.ListLists: invalid next link '%s', error: %w", data.NextLink, err)
+		}
+	}
+}
+
+// GetListByTitle fetch a list or document library of a site by its title.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#getbytitle-method
+func (c *Client) GetListByTitle(ctx context.Context, siteWebURL, title string) (*List, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// single quotes are escaped by doubling them in OData literals
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/lists/getbytitle('%s')", strings.ReplaceAll(title, "'", "''")))
+	query := url.Query()
+	query.Set("$select", "Id,Title,HasUniqueRoleAssignments,Hidden,BaseType")
+	url.RawQuery = query.Encode()
+
+	var data List
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.GetListByTitle: %w", err)
+	}
+
+	return &data, nil
+}
+
+// ListListItems list a page of the items (files and folders) of a list or
+// document library, nextLink is empty for the first page. The link to the
+// next page is returned, if any.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#listitemcollection-resource
+func (c *Client) ListListItems(ctx context.Context, siteWebURL, listID, nextLink string, top int) ([]ListItem, string, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/lists(guid'%s')/items", listID))
+	query := url.Query()
+	query.Set("$select", "Id,FileSystemObjectType,HasUniqueRoleAssignments,FileRef,FileLeafRef")
+	query.Set("$top", strconv.Itoa(top))
+	url.RawQuery = query.Encode()
+
+	if nextLink != "" {
+		url, err = url.Parse(nextLink)
+		if err != nil {
+			return nil, "", fmt.Errorf("Client.ListListItems: invalid next link '%s', error: %w", nextLink, err)
+		}
+	}
+
+	var data ListListItemsResponse
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, "", fmt.Errorf("Client.ListListItems: %w", err)
+	}
+
+	return data.Value, data.NextLink, nil
+}
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// PermissionKind is a SP.PermissionKind, each value is the (1-based)
+// position of the right inside the 64-bit BasePermissions mask
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/sharepoint-csom/ee536458(v=office.15)
+type PermissionKind int
+
+const (
+	EmptyMask                     PermissionKind = 0
+	ViewListItems                 PermissionKind = 1
+	AddListItems                  PermissionKind = 2
+	EditListItems                 PermissionKind = 3
+	DeleteListItems               PermissionKind = 4
+	ApproveItems                  PermissionKind = 5
+	OpenItems                     PermissionKind = 6
+	ViewVersions                  PermissionKind = 7
+	DeleteVersions                PermissionKind = 8
+	CancelCheckout                PermissionKind = 9
+	ManagePersonalViews           PermissionKind = 10
+	ManageLists                   PermissionKind = 12
+	ViewFormPages                 PermissionKind = 13
+	AnonymousSearchAccessList     PermissionKind = 14
+	Open                          PermissionKind = 17
+	ViewPages                     PermissionKind = 18
+	AddAndCustomizePages          PermissionKind = 19
+	ApplyThemeAndBorder           PermissionKind = 20
+	ApplyStyleSheets              PermissionKind = 21
+	ViewUsageData                 PermissionKind = 22
+	CreateSSCSite                 PermissionKind = 23
+	ManageSubwebs                 PermissionKind = 24
+	CreateGroups                  PermissionKind = 25
+	ManagePermissions             PermissionKind = 26
+	BrowseDirectories             PermissionKind = 27
+	BrowseUserInfo                PermissionKind = 28
+	AddDelPrivateWebParts         PermissionKind = 29
+	UpdatePersonalWebParts        PermissionKind = 30
+	ManageWeb                     PermissionKind = 31
+	AnonymousSearchAccessWebLists PermissionKind = 32
+	UseClientIntegration          PermissionKind = 37
+	UseRemoteAPIs                 PermissionKind = 38
+	ManageAlerts                  PermissionKind = 39
+	CreateAlerts                  PermissionKind = 40
+	EditMyUserInfo                PermissionKind = 41
+	EnumeratePermissions          PermissionKind = 63
+	FullMask                      PermissionKind = 65
+)
+
+// permissionKinds is every right that can be set on a mask, in the
+// same order the SharePoint UI shows them.
+var permissionKinds = []PermissionKind{
+	ViewListItems, AddListItems, EditListItems, DeleteListItems, ApproveItems, OpenItems, ViewVersions,
+	DeleteVersions, CancelCheckout, ManagePersonalViews, ManageLists, ViewFormPages, AnonymousSearchAccessList,
+	Open, ViewPages, AddAndCustomizePages, ApplyThemeAndBorder, ApplyStyleSheets, ViewUsageData, CreateSSCSite,
+	ManageSubwebs, CreateGroups, ManagePermissions, BrowseDirectories, BrowseUserInfo, AddDelPrivateWebParts,
+	UpdatePersonalWebParts, ManageWeb, AnonymousSearchAccessWebLists, UseClientIntegration, UseRemoteAPIs,
+	ManageAlerts, CreateAlerts, EditMyUserInfo, EnumeratePermissions,
+}
+
+func (k PermissionKind) String() string {
+	value := ""
+	switch k {
+	case EmptyMask:
+		value = "EmptyMask"
+	case ViewListItems:
+		value = "ViewListItems"
+	case AddListItems:
+		value = "AddListItems"
+	case EditListItems:
+		value = "EditListItems"
+	case DeleteListItems:
+		value = "DeleteListItems"
+	case ApproveItems:
+		value = "ApproveItems"
+	case OpenItems:
+		value = "OpenItems"
+	case ViewVersions:
+		value = "ViewVersions"
+	case DeleteVersions:
+		value = "DeleteVersions"
+	case CancelCheckout:
+		value = "CancelCheckout"
+	case ManagePersonalViews:
+		value = "ManagePersonalViews"
+	case ManageLists:
+		value = "ManageLists"
+	case ViewFormPages:
+		value = "ViewFormPages"
+	case AnonymousSearchAccessList:
+		value = "AnonymousSearchAccessList"
+	case Open:
+		value = "Open"
+	case ViewPages:
+		value = "ViewPages"
+	case AddAndCustomizePages:
+		value = "AddAndCustomizePages"
+	case ApplyThemeAndBorder:
+		value = "ApplyThemeAndBorder"
+	case ApplyStyleSheets:
+		value = "ApplyStyleSheets"
+	case ViewUsageData:
+		value = "ViewUsageData"
+	case CreateSSCSite:
+		value = "CreateSSCSite"
+	case ManageSubwebs:
+		value = "ManageSubwebs"
+	case CreateGroups:
+		value = "CreateGroups"
+	case ManagePermissions:
+		value = "ManagePermissions"
+	case BrowseDirectories:
+		value = "BrowseDirectories"
+	case BrowseUserInfo:
+		value = "BrowseUserInfo"
+	case AddDelPrivateWebParts:
+		value = "AddDelPrivateWebParts"
+	case UpdatePersonalWebParts:
+		value = "UpdatePersonalWebParts"
+	case ManageWeb:
+		value = "ManageWeb"
+	case AnonymousSearchAccessWebLists:
+		value = "AnonymousSearchAccessWebLists"
+	case UseClientIntegration:
+		value = "UseClientIntegration"
+	case UseRemoteAPIs:
+		value = "UseRemoteAPIs"
+	case ManageAlerts:
+		value = "ManageAlerts"
+	case CreateAlerts:
+		value = "CreateAlerts"
+	case EditMyUserInfo:
+		value = "EditMyUserInfo"
+	case EnumeratePermissions:
+		value = "EnumeratePermissions"
+	case FullMask:
+		value = "FullMask"
+	}
+
+	return value
+}
+
+// Mask parses the two halves of the base permissions into a single 64-bit mask.
+func (b BasePermissions) Mask() (uint64, error) {
+	high, err := parseMaskHalf(b.High)
+	if err != nil {
+		return 0, fmt.Errorf("invalid high bits of base permissions '%s', error: %w", b.High, err)
+	}
+	low, err := parseMaskHalf(b.Low)
+	if err != nil {
+		return 0, fmt.Errorf("invalid low bits of base permissions '%s', error: %w", b.Low, err)
+	}
+
+	return high<<32 | low, nil
+}
+
+// Rights decodes the base permissions into the list of rights it allows.
+// Masks with every right set are reported as FullMask alone.
+func (b BasePermissions) Rights() ([]PermissionKind, error) {
+	mask, err := b.Mask()
+	if err != nil {
+		return nil, err
+	}
+
+	// SharePoint never sets the highest bit of the mask
+	if mask == math.MaxInt64 {
+		return []PermissionKind{FullMask}, nil
+	}
+
+	var ret []PermissionKind
+	for _, kind := range permissionKinds {
+		if mask&(1<<(kind-1)) != 0 {
+			ret = append(ret, kind)
+		}
+	}
+
+	return ret, nil
+}
+
+func parseMaskHalf(value json.Number) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseUint(value.String(), 10, 32)
+}
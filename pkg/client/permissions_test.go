@@ -0,0 +1,61 @@
+package client
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestBasePermissionsRights(t *testing.T) {
+	testCases := []struct {
+		name     string
+		payload  string
+		expected []PermissionKind
+	}{
+		{
+			name:     "full control",
+			payload:  `{"High":"2147483647","Low":"4294967295"}`,
+			expected: []PermissionKind{FullMask},
+		},
+		{
+			name:     "empty",
+			payload:  `{"High":"0","Low":"0"}`,
+			expected: nil,
+		},
+		{
+			name:     "bits on both halves",
+			payload:  `{"High":"16","Low":"1073741827"}`,
+			expected: []PermissionKind{ViewListItems, AddListItems, ManageWeb, UseClientIntegration},
+		},
+		{
+			// bit 33 isn't a right SharePoint defines, so it's dropped
+			name:     "unquoted numbers",
+			payload:  `{"High":1,"Low":4}`,
+			expected: []PermissionKind{EditListItems},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var perms BasePermissions
+			if err := json.Unmarshal([]byte(tc.payload), &perms); err != nil {
+				t.Fatalf("cannot unmarshal payload, error: %v", err)
+			}
+
+			rights, err := perms.Rights()
+			if err != nil {
+				t.Fatalf("cannot decode rights, error: %v", err)
+			}
+			if !slices.Equal(rights, tc.expected) {
+				t.Errorf("got %v, expected %v", rights, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBasePermissionsRightsInvalid(t *testing.T) {
+	perms := BasePermissions{High: "4294967296", Low: "0"}
+	if _, err := perms.Rights(); err == nil {
+		t.Error("expected an error for a high half that doesn't fit in 32 bits")
+	}
+}
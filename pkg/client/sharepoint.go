@@ -9,7 +9,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/conductorone/baton-sharepoint/pkg/errorexplained"
 )
@@ -20,9 +19,7 @@ import (
 //                pagination.
 
 func (c *Client) ListGroupsForSite(ctx context.Context, siteWebURL string) ([]SharePointSiteGroup, error) {
-	bearer, err := c.certbasedToken.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{fmt.Sprintf(scopeSharePointTemplate, c.sharePointDomain)},
-	})
+	bearer, err := c.sharePointToken(ctx, siteWebURL)
 	if err != nil {
 		return nil, fmt.Errorf("Client.ListGroupsForSite: failed to fetch bearer token, error: %w", err)
 	}
@@ -35,7 +32,7 @@ func (c *Client) ListGroupsForSite(ctx context.Context, siteWebURL string) ([]Sh
 	reqOpts := []uhttp.RequestOption{
 		uhttp.WithAcceptJSONHeader(),
 		uhttp.WithContentTypeJSONHeader(),
-		uhttp.WithBearerToken(bearer.Token),
+		uhttp.WithBearerToken(bearer),
 	}
 
 	url.Path = path.Join(url.Path, "/_api/web/sitegroups")
@@ -65,15 +62,43 @@ func (c *Client) ListGroupsForSite(ctx context.Context, siteWebURL string) ([]Sh
 	return filtered, nil
 }
 
-func (c *Client) ListSecurityPrincipalsInGroupByGroupID(ctx context.Context, groupURLID string) ([]SecurityPrincipal, error) {
-	bearer, err := c.certbasedToken.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{fmt.Sprintf(scopeSharePointTemplate, c.sharePointDomain)},
-	})
+// GetWebWithAssociatedGroups fetch the web of a site along with its
+// associated owner, member and visitor groups.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#web-properties
+func (c *Client) GetWebWithAssociatedGroups(ctx context.Context, siteWebURL string) (*Web, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web")
+	query := url.Query()
+	query.Set("$expand", "AssociatedOwnerGroup,AssociatedMemberGroup,AssociatedVisitorGroup")
+	query.Set("$select", strings.Join([]string{
+		"Id", "Title",
+		"AssociatedOwnerGroup/Id", "AssociatedOwnerGroup/Title", "AssociatedOwnerGroup/LoginName",
+		"AssociatedMemberGroup/Id", "AssociatedMemberGroup/Title", "AssociatedMemberGroup/LoginName",
+		"AssociatedVisitorGroup/Id", "AssociatedVisitorGroup/Title", "AssociatedVisitorGroup/LoginName",
+	}, ","))
+	url.RawQuery = query.Encode()
+
+	var data Web
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.GetWebWithAssociatedGroups: %w", err)
+	}
+
+	return &data, nil
+}
+
+func (c *Client) ListSecurityPrincipalsInGroupByGroupID(ctx context.Context, siteWebURL string, groupID int) ([]SecurityPrincipal, error) {
+	bearer, err := c.sharePointToken(ctx, siteWebURL)
 	if err != nil {
 		return nil, fmt.Errorf("Client.ListUsersInGroupByGroupID: failed to fetch bearer token, error: %w", err)
 	}
 
-	url, err := url.Parse(groupURLID)
+	url, err := url.Parse(siteWebURL)
 	if err != nil {
 		return nil, err
 	}
@@ -81,10 +106,10 @@ func (c *Client) ListSecurityPrincipalsInGroupByGroupID(ctx context.Context, gro
 	reqOpts := []uhttp.RequestOption{
 		uhttp.WithAcceptJSONHeader(),
 		uhttp.WithContentTypeJSONHeader(),
-		uhttp.WithBearerToken(bearer.Token),
+		uhttp.WithBearerToken(bearer),
 	}
 
-	url.Path = path.Join(url.Path, "Users")
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/sitegroups/getbyid(%d)/users", groupID))
 	req, err := c.http.NewRequest(ctx, http.MethodGet, url, reqOpts...)
 	if err != nil {
 		return nil, err
@@ -96,10 +121,10 @@ func (c *Client) ListSecurityPrincipalsInGroupByGroupID(ctx context.Context, gro
 	if err != nil {
 		altMessage := ""
 		if strings.Contains(err.Error(), "403 Forbidden") && !c.dontFilterSharePointSpecialGroups {
-			altMessage = fmt.Sprintf("access to the user list of group '%s' was denied, are we trying to list users of a 'special' group?", groupURLID)
+			altMessage = fmt.Sprintf("access to the user list of group %d of site '%s' was denied, are we trying to list users of a 'special' group?", groupID, siteWebURL)
 		} else if strings.Contains(err.Error(), "403 Forbidden") && c.dontFilterSharePointSpecialGroups {
-			altMessage = fmt.Sprintf("access to the user list of group '%s' was denied, check that admin consent was "+
-				"granted for API permission SharePoint > Sites.FullControl.All for your registered app", groupURLID)
+			altMessage = fmt.Sprintf("access to the user list of group %d of site '%s' was denied, check that admin consent was "+
+				"granted for API permission SharePoint > Sites.FullControl.All for your registered app", groupID, siteWebURL)
 		}
 		return nil, errorexplained.WhatErrorToReturn(queryErr, err, altMessage)
 	}
@@ -110,9 +135,7 @@ func (c *Client) ListSecurityPrincipalsInGroupByGroupID(ctx context.Context, gro
 }
 
 func (c *Client) ListSecurityPrincipals(ctx context.Context, siteWebURL string) ([]SecurityPrincipal, error) {
-	bearer, err := c.certbasedToken.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{fmt.Sprintf(scopeSharePointTemplate, c.sharePointDomain)},
-	})
+	bearer, err := c.sharePointToken(ctx, siteWebURL)
 	if err != nil {
 		return nil, fmt.Errorf("Client.ListSharePointUsers: failed to fetch bearer token, error: %w", err)
 	}
@@ -125,7 +148,7 @@ func (c *Client) ListSecurityPrincipals(ctx context.Context, siteWebURL string)
 	reqOpts := []uhttp.RequestOption{
 		uhttp.WithAcceptJSONHeader(),
 		uhttp.WithContentTypeJSONHeader(),
-		uhttp.WithBearerToken(bearer.Token),
+		uhttp.WithBearerToken(bearer),
 	}
 
 	url.Path = path.Join(url.Path, "_api/web/siteusers")
@@ -151,3 +174,301 @@ func (c *Client) ListSecurityPrincipals(ctx context.Context, siteWebURL string)
 
 	return data.Value, nil
 }
+
+// ListRoleDefinitions list the permission levels defined on a site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roledefinitioncollection-resource
+func (c *Client) ListRoleDefinitions(ctx context.Context, siteWebURL string) ([]RoleDefinition, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web/roledefinitions")
+
+	var data ListRoleDefinitionsResponse
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.ListRoleDefinitions: %w", err)
+	}
+
+	return data.Value, nil
+}
+
+// ListRoleAssignments list who was given which permission levels on a site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roleassignmentcollection-resource
+func (c *Client) ListRoleAssignments(ctx context.Context, siteWebURL string) ([]RoleAssignment, error) {
+	ret, err := c.listRoleAssignments(ctx, siteWebURL, "_api/web")
+	if err != nil {
+		return nil, fmt.Errorf("Client.ListRoleAssignments: %w", err)
+	}
+
+	return ret, nil
+}
+
+// ListListRoleAssignments list who was given which permission levels on a list or document library.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roleassignmentcollection-resource
+func (c *Client) ListListRoleAssignments(ctx context.Context, siteWebURL, listID string) ([]RoleAssignment, error) {
+	ret, err := c.listRoleAssignments(ctx, siteWebURL, fmt.Sprintf("_api/web/lists(guid'%s')", listID))
+	if err != nil {
+		return nil, fmt.Errorf("Client.ListListRoleAssignments: %w", err)
+	}
+
+	return ret, nil
+}
+
+// ListListItemRoleAssignments list who was given which permission levels on a file or folder.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roleassignmentcollection-resource
+func (c *Client) ListListItemRoleAssignments(ctx context.Context, siteWebURL, listID string, itemID int) ([]RoleAssignment, error) {
+	ret, err := c.listRoleAssignments(ctx, siteWebURL, fmt.Sprintf("_api/web/lists(guid'%s')/items(%d)", listID, itemID))
+	if err != nil {
+		return nil, fmt.Errorf("Client.ListListItemRoleAssignments: %w", err)
+	}
+
+	return ret, nil
+}
+
+// listRoleAssignments list the role assignments of the securable object
+// found at securablePath (relative to the site).
+func (c *Client) listRoleAssignments(ctx context.Context, siteWebURL, securablePath string) ([]RoleAssignment, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, securablePath, "roleassignments")
+	query := url.Query()
+	query.Set("$expand", "Member,RoleDefinitionBindings")
+	url.RawQuery = query.Encode()
+
+	var data ListRoleAssignmentsResponse
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Value, nil
+}
+
+// SetSiteAdmin sets or unsets the user of the site as site collection administrator.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#user-properties
+func (c *Client) SetSiteAdmin(ctx context.Context, siteWebURL string, userID int, isSiteAdmin bool) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/siteusers/getbyid(%d)", userID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, map[string]bool{"IsSiteAdmin": isSiteAdmin}, nil,
+		uhttp.WithHeader("X-HTTP-Method", "MERGE"),
+		uhttp.WithHeader("IF-MATCH", "*"),
+	)
+	if err != nil {
+		return fmt.Errorf("Client.SetSiteAdmin: %w", err)
+	}
+
+	return nil
+}
+
+// sharePointQuery sends a request to the SharePoint REST API with the
+// certificate based token of its host, the response is returned so callers can
+// inspect its status code.
+func (c *Client) sharePointQuery(ctx context.Context, method string, u *url.URL, body, res any, extraOpts ...uhttp.RequestOption) (*http.Response, error) {
+	bearer, err := c.sharePointToken(ctx, u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bearer token, error: %w", err)
+	}
+
+	reqOpts := []uhttp.RequestOption{
+		uhttp.WithAcceptJSONHeader(),
+		uhttp.WithContentTypeJSONHeader(),
+		uhttp.WithBearerToken(bearer),
+	}
+	if body != nil {
+		reqOpts = append(reqOpts, uhttp.WithJSONBody(body))
+	}
+	reqOpts = append(reqOpts, extraOpts...)
+
+	req, err := c.http.NewRequest(ctx, method, u, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var queryErr errorexplained.ErrorExplained
+	doOpts := []uhttp.DoOption{
+		uhttp.WithErrorResponse(&queryErr),
+	}
+	if res != nil {
+		doOpts = append(doOpts, uhttp.WithJSONResponse(res))
+	}
+
+	resp, err := c.http.Do(req, doOpts...)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		return resp, errorexplained.WhatErrorToReturn(queryErr, err, "")
+	}
+
+	return resp, nil
+}
+
+// EnsureUser checks whether the specified logon name belongs to a valid
+// user of the site, if the user doesn't exists it's added to the site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#ensureuser-method
+func (c *Client) EnsureUser(ctx context.Context, siteWebURL, logonName string) (*SecurityPrincipal, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web/ensureuser")
+
+	var data SecurityPrincipal
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, map[string]string{"logonName": logonName}, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.EnsureUser: %w", err)
+	}
+
+	return &data, nil
+}
+
+// AddSecurityPrincipalToGroup adds the user or group with the given login name to a SharePoint group.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#usercollection-resource
+func (c *Client) AddSecurityPrincipalToGroup(ctx context.Context, siteWebURL string, groupID int, loginName string) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/sitegroups/getbyid(%d)/users", groupID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, map[string]string{"LoginName": loginName}, nil)
+	if err != nil {
+		return fmt.Errorf("Client.AddSecurityPrincipalToGroup: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveSecurityPrincipalFromGroup removes the user or group with the given login name from a SharePoint group.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#removebyloginname-method
+func (c *Client) RemoveSecurityPrincipalFromGroup(ctx context.Context, siteWebURL string, groupID int, loginName string) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/sitegroups/getbyid(%d)/users/removeByLoginName", groupID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, map[string]string{"loginName": loginName}, nil)
+	if err != nil {
+		return fmt.Errorf("Client.RemoveSecurityPrincipalFromGroup: %w", err)
+	}
+
+	return nil
+}
+
+// CreateGroup creates a SharePoint group on a site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#groupcollection-resource
+func (c *Client) CreateGroup(ctx context.Context, siteWebURL string, group SharePointSiteGroupCreation) (*SharePointSiteGroup, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web/sitegroups")
+
+	var data SharePointSiteGroup
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, &group, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.CreateGroup: %w", err)
+	}
+
+	return &data, nil
+}
+
+// SetGroupOwner makes the user or group with the given ID the owner of a SharePoint group.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#setuserasowner-method
+func (c *Client) SetGroupOwner(ctx context.Context, siteWebURL string, groupID, ownerID int) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/sitegroups/getbyid(%d)/SetUserAsOwner(%d)", groupID, ownerID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Client.SetGroupOwner: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteGroup deletes a SharePoint group of a site.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#removebyid-method
+func (c *Client) DeleteGroup(ctx context.Context, siteWebURL string, groupID int) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/sitegroups/removebyid(%d)", groupID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Client.DeleteGroup: %w", err)
+	}
+
+	return nil
+}
+
+// AddRoleAssignment gives a permission level on a site to the user or group with the given ID.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#addroleassignment-method
+func (c *Client) AddRoleAssignment(ctx context.Context, siteWebURL string, principalID, roleDefinitionID int) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/roleassignments/addroleassignment(principalid=%d,roledefid=%d)", principalID, roleDefinitionID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Client.AddRoleAssignment: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveRoleAssignment takes a permission level on a site away from the user or group with the given ID.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#removeroleassignment-method
+func (c *Client) RemoveRoleAssignment(ctx context.Context, siteWebURL string, principalID, roleDefinitionID int) error {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return err
+	}
+
+	url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/roleassignments/removeroleassignment(principalid=%d,roledefid=%d)", principalID, roleDefinitionID))
+
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, nil, nil)
+	if err != nil {
+		return fmt.Errorf("Client.RemoveRoleAssignment: %w", err)
+	}
+
+	return nil
+}
@@ -1,5 +1,7 @@
 package client
 
+import "encoding/json"
+
 // UserOrGroupPrincipalType Specifies the type of a principal for either Users or Groups
 // documentation: https://learn.microsoft.com/en-us/previous-versions/office/sharepoint-csom/ee541430(v=office.15)#members
 // Note(shackra): Please note that the bitwise operation is not implemented yet.
@@ -69,6 +71,18 @@ type SharePointSiteGroup struct {
 	PrincipalType int `json:"PrincipalType"`
 }
 
+// SharePointSiteGroupCreation holds the writable properties of a SP.Group, used to create groups
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#group-properties
+type SharePointSiteGroupCreation struct {
+	Title                          string `json:"Title"`
+	Description                    string `json:"Description,omitempty"`
+	AllowMembersEditMembership     bool   `json:"AllowMembersEditMembership"`
+	AllowRequestToJoinLeave        bool   `json:"AllowRequestToJoinLeave"`
+	AutoAcceptRequestToJoinLeave   bool   `json:"AutoAcceptRequestToJoinLeave"`
+	OnlyAllowMembersViewMembership bool   `json:"OnlyAllowMembersViewMembership"`
+	RequestToJoinLeaveEmailSetting string `json:"RequestToJoinLeaveEmailSetting,omitempty"`
+}
+
 // SharePointUserId is a SP.UserIdInfo
 // documentation: (check the link to the documentation on the `SharePointUser` struct)
 type SharePointUserId struct {
@@ -96,6 +110,119 @@ type SecurityPrincipal struct {
 	UserPrincipalName              string `json:"UserPrincipalName"`
 }
 
+// Web is a SP.Web, expanded with its associated groups
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#web-properties
+type Web struct {
+	ODataID   string `json:"odata.id"`
+	ODataType string `json:"odata.type"`
+	Id        string `json:"Id"`    // Gets a value that specifies the site identifier for the site.
+	Title     string `json:"Title"` // Gets or sets the title for the site.
+	Url       string `json:"Url"`   // Gets the absolute URL for the website.
+	// Gets the server-relative URL for the website.
+	ServerRelativeUrl string `json:"ServerRelativeUrl"`
+	// Gets a value that specifies whether the role assignments are uniquely defined for this securable object or inherited from a parent securable object. Only present when selected.
+	HasUniqueRoleAssignments bool `json:"HasUniqueRoleAssignments"`
+	// Gets or sets the associated owner group of the site. Only present when expanded.
+	AssociatedOwnerGroup *SharePointSiteGroup `json:"AssociatedOwnerGroup"`
+	// Gets or sets the associated member group of the site. Only present when expanded.
+	AssociatedMemberGroup *SharePointSiteGroup `json:"AssociatedMemberGroup"`
+	// Gets or sets the associated visitor group of the site. Only present when expanded.
+	AssociatedVisitorGroup *SharePointSiteGroup `json:"AssociatedVisitorGroup"`
+}
+
+// List is a SP.List, either a list or a document library
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#list-properties
+type List struct {
+	ODataID   string `json:"odata.id"`
+	ODataType string `json:"odata.type"`
+	Id        string `json:"Id"`       // Gets a value that specifies the list identifier.
+	Title     string `json:"Title"`    // Gets or sets the displayed title for the list.
+	Hidden    bool   `json:"Hidden"`   // Gets or sets a Boolean value that specifies whether the list is hidden.
+	BaseType  int    `json:"BaseType"` // Gets the list server template, 1 (DocumentLibrary) for document libraries.
+	// Gets a value that specifies whether the role assignments are uniquely defined for this securable object or inherited from a parent securable object.
+	HasUniqueRoleAssignments bool `json:"HasUniqueRoleAssignments"`
+}
+
+// ListBaseTypeDocumentLibrary is the BaseType of document libraries.
+const ListBaseTypeDocumentLibrary = 1
+
+// ListItem is a SP.ListItem, either a file or a folder in document libraries
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#listitem-properties
+type ListItem struct {
+	ODataID   string `json:"odata.id"`
+	ODataType string `json:"odata.type"`
+	Id        int    `json:"Id"` // Gets a value that specifies the list item identifier.
+	// Gets a value that specifies whether the list item is a file or a list folder.
+	FileSystemObjectType FileSystemObjectType `json:"FileSystemObjectType"`
+	// Gets a value that specifies whether the role assignments are uniquely defined for this securable object or inherited from a parent securable object.
+	HasUniqueRoleAssignments bool   `json:"HasUniqueRoleAssignments"`
+	FileRef                  string `json:"FileRef"`     // Server-relative URL of the file or folder.
+	FileLeafRef              string `json:"FileLeafRef"` // Name of the file or folder.
+}
+
+// FileSystemObjectType Specifies the file system object type
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/sharepoint-csom/ee537053(v=office.15)
+type FileSystemObjectType int
+
+const (
+	FileSystemObjectTypeInvalid FileSystemObjectType = -1
+	FileSystemObjectTypeFile    FileSystemObjectType = 0
+	FileSystemObjectTypeFolder  FileSystemObjectType = 1
+	FileSystemObjectTypeWeb     FileSystemObjectType = 2
+)
+
+// SharedListItem is the SP.ListItem a SharePoint sharing link points to, expanded with its `ParentList`
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531433(v=office.15)#listitem-properties
+type SharedListItem struct {
+	Id         int `json:"Id"` // Gets a value that specifies the list item identifier.
+	ParentList struct {
+		Id string `json:"Id"` // Gets a value that specifies the list identifier.
+	} `json:"ParentList"`
+}
+
+// SharePointSite is a SP.Site, i.e. a site collection
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499821(v=office.15)#site-properties
+type SharePointSite struct {
+	ODataID   string `json:"odata.id"`
+	ODataType string `json:"odata.type"`
+	Id        string `json:"Id"`  // Gets the GUID that identifies the site collection.
+	Url       string `json:"Url"` // Gets the full URL to the root Web site of the site collection.
+}
+
+// BasePermissions is a SP.BasePermissions, the 64-bit mask is split in two 32-bit halves
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/sharepoint-csom/ee543321(v=office.15)
+type BasePermissions struct {
+	High json.Number `json:"High"` // Gets the upper 32 bits of the mask. Edm.Int64 may be serialized as a string.
+	Low  json.Number `json:"Low"`  // Gets the lower 32 bits of the mask. Edm.Int64 may be serialized as a string.
+}
+
+// RoleDefinition is a SP.RoleDefinition, also known as "permission level" on the SharePoint UI
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roledefinition-properties
+type RoleDefinition struct {
+	ODataID         string          `json:"odata.id"`
+	ODataType       string          `json:"odata.type"`
+	Id              int             `json:"Id"`              // Gets a value that specifies the role definition identifier.
+	Name            string          `json:"Name"`            // Gets or sets a value that specifies the role definition name.
+	Description     string          `json:"Description"`     // Gets or sets a value that specifies the description of the role definition.
+	Hidden          bool            `json:"Hidden"`          // Gets a value that specifies whether the role definition is displayed.
+	Order           int             `json:"Order"`           // Gets or sets a value that specifies the order position of the object in the site collection Permission Levels page.
+	RoleTypeKind    int             `json:"RoleTypeKind"`    // Gets a value that specifies the type of the role definition.
+	BasePermissions BasePermissions `json:"BasePermissions"` // Gets or sets a value that specifies the base permissions for the role definition.
+}
+
+// RoleAssignment is a SP.RoleAssignment, expanded with its `Member` and `RoleDefinitionBindings`
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn531432(v=office.15)#roleassignment-resource
+type RoleAssignment struct {
+	ODataID     string `json:"odata.id"`
+	ODataType   string `json:"odata.type"`
+	PrincipalId int    `json:"PrincipalId"` // Gets the unique identifier of the role assignment.
+	// Gets the user or group that corresponds to the role assignment. The
+	// fields shared by SP.User and SP.Group are all we need.
+	Member SecurityPrincipal `json:"Member"`
+	// Gets the collection of role definition bindings for the role assignment.
+	RoleDefinitionBindings []RoleDefinition `json:"RoleDefinitionBindings"`
+}
+
 // Local Variables:
 // go-tag-args: ("-transform" "pascalcase")
 // End:
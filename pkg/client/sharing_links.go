@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// GetListItemByUniqueID finds the list item of a file or folder by its
+// unique ID, the one SharePoint uses in the title of `SharingLinks` groups.
+// Only the files and folders of the web at webURL are found, not the ones
+// of its subsites.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#getfilebyid-method
+func (c *Client) GetListItemByUniqueID(ctx context.Context, webURL, uniqueID string) (*SharedListItem, error) {
+	var lastErr error
+	for _, getter := range []string{"GetFileById", "GetFolderById"} {
+		url, err := url.Parse(webURL)
+		if err != nil {
+			return nil, err
+		}
+
+		url.Path = path.Join(url.Path, fmt.Sprintf("_api/web/%s('%s')/ListItemAllFields", getter, uniqueID))
+		query := url.Query()
+		query.Set("$select", "Id,ParentList/Id")
+		query.Set("$expand", "ParentList")
+		url.RawQuery = query.Encode()
+
+		var data SharedListItem
+		_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+		if err == nil {
+			return &data, nil
+		}
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("Client.GetListItemByUniqueID: %w", lastErr)
+}
+
+// GetDriveItemOfListItem fetch the drive item of a list item of a document library.
+//
+// Permission required: `Sites.Read.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/listitem-get
+func (c *Client) GetDriveItemOfListItem(ctx context.Context, siteID, listID string, itemID int) (*DriveItem, error) {
+	defaultValues := url.Values{}
+	defaultValues.Set("$select", strings.Join([]string{"id", "name", "webUrl", "parentReference"}, ","))
+
+	targetURL := c.buildURL(path.Join("sites", siteID, "lists", listID, "items", fmt.Sprint(itemID), "driveItem"), defaultValues)
+
+	var resp DriveItem
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp, WithoutEventualConsistency())
+	if err != nil {
+		return nil, fmt.Errorf("GetDriveItemOfListItem: request failed, error: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// ListDriveItemPermissions list the permissions of a drive item, sharing
+// links among them.
+//
+// Permission required: `Sites.Read.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/driveitem-list-permissions
+func (c *Client) ListDriveItemPermissions(ctx context.Context, driveID, itemID string) ([]DrivePermission, error) {
+	targetURL := c.buildURL(path.Join("drives", driveID, "items", itemID, "permissions"), url.Values{})
+
+	var resp ListDriveItemPermissionsResponse
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp, WithoutEventualConsistency())
+	if err != nil {
+		return nil, fmt.Errorf("ListDriveItemPermissions: request failed, error: %w", err)
+	}
+
+	return resp.Value, nil
+}
+
+// GetDriveItemPermission fetch a permission of a drive item.
+//
+// Permission required: `Sites.Read.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/permission-get
+func (c *Client) GetDriveItemPermission(ctx context.Context, driveID, itemID, permissionID string) (*DrivePermission, error) {
+	targetURL := c.buildURL(path.Join("drives", driveID, "items", itemID, "permissions", permissionID), url.Values{})
+
+	var resp DrivePermission
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp, WithoutEventualConsistency())
+	if err != nil {
+		return nil, fmt.Errorf("GetDriveItemPermission: request failed, error: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// RevokeDrivePermissionGrants revokes the access of the recipients to a sharing link.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/permission-revokegrants
+func (c *Client) RevokeDrivePermissionGrants(ctx context.Context, driveID, itemID, permissionID string, grantees []DriveRecipient) error {
+	targetURL := c.buildURL(path.Join("drives", driveID, "items", itemID, "permissions", permissionID, "revokeGrants"), url.Values{})
+
+	body := struct {
+		Grantees []DriveRecipient `json:"grantees"`
+	}{Grantees: grantees}
+
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodPost, targetURL, &body, nil, WithoutEventualConsistency())
+	if err != nil {
+		return fmt.Errorf("RevokeDrivePermissionGrants: request failed, error: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDrivePermission removes a permission of a drive item, deleting a
+// sharing link makes it stop working for everyone.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/permission-delete
+func (c *Client) DeleteDrivePermission(ctx context.Context, driveID, itemID, permissionID string) error {
+	targetURL := c.buildURL(path.Join("drives", driveID, "items", itemID, "permissions", permissionID), url.Values{})
+
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodDelete, targetURL, nil, nil, WithoutEventualConsistency())
+	if err != nil {
+		return fmt.Errorf("DeleteDrivePermission: request failed, error: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// Roles an application can be given on a site through Sites.Selected.
+const (
+	SitePermissionRead        = "read"
+	SitePermissionWrite       = "write"
+	SitePermissionManage      = "manage"
+	SitePermissionFullControl = "fullcontrol"
+)
+
+// SitePermissionRoles lists every role of Sites.Selected, from least to most privileged.
+var SitePermissionRoles = []string{SitePermissionRead, SitePermissionWrite, SitePermissionManage, SitePermissionFullControl}
+
+// ListSitePermissions list the permissions granted to applications on a
+// site, i.e. the ones granted for Sites.Selected.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-list-permissions
+func (c *Client) ListSitePermissions(ctx context.Context, siteID string) ([]SitePermission, error) {
+	targetURL := c.buildURL(path.Join("sites", siteID, "permissions"), url.Values{})
+
+	var ret []SitePermission
+	for targetURL != "" {
+		var resp ListSitePermissionsResponse
+		err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp, WithoutEventualConsistency())
+		if err != nil {
+			return nil, fmt.Errorf("ListSitePermissions: request failed, error: %w", err)
+		}
+
+		ret = append(ret, resp.Value...)
+		targetURL = resp.NextLink
+	}
+
+	return ret, nil
+}
+
+// GetSitePermission fetch a permission granted to applications on a
+// site, unlike ListSitePermissions its roles are always returned.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-get-permission
+func (c *Client) GetSitePermission(ctx context.Context, siteID, permissionID string) (*SitePermission, error) {
+	targetURL := c.buildURL(path.Join("sites", siteID, "permissions", permissionID), url.Values{})
+
+	var resp SitePermission
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp, WithoutEventualConsistency())
+	if err != nil {
+		return nil, fmt.Errorf("GetSitePermission: request failed, error: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// CreateSitePermission grants a role on a site to an application.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-post-permissions
+func (c *Client) CreateSitePermission(ctx context.Context, siteID, role, appID, appDisplayName string) (*SitePermission, error) {
+	targetURL := c.buildURL(path.Join("sites", siteID, "permissions"), url.Values{})
+
+	body := SitePermission{
+		Roles: []string{role},
+		GrantedToIdentities: []IdentitySet{
+			{Application: &Identity{ID: appID, DisplayName: appDisplayName}},
+		},
+	}
+
+	var resp SitePermission
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodPost, targetURL, &body, &resp, WithoutEventualConsistency())
+	if err != nil {
+		return nil, fmt.Errorf("CreateSitePermission: request failed, error: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// UpdateSitePermissionRoles replaces the roles of a permission granted to
+// applications on a site.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-update-permission
+func (c *Client) UpdateSitePermissionRoles(ctx context.Context, siteID, permissionID string, roles []string) error {
+	targetURL := c.buildURL(path.Join("sites", siteID, "permissions", permissionID), url.Values{})
+
+	body := SitePermission{Roles: roles}
+
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodPatch, targetURL, &body, nil, WithoutEventualConsistency())
+	if err != nil {
+		return fmt.Errorf("UpdateSitePermissionRoles: request failed, error: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteSitePermission removes a permission granted to applications on a site.
+//
+// Permission required: `Sites.FullControl.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-delete-permission
+func (c *Client) DeleteSitePermission(ctx context.Context, siteID, permissionID string) error {
+	targetURL := c.buildURL(path.Join("sites", siteID, "permissions", permissionID), url.Values{})
+
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodDelete, targetURL, nil, nil, WithoutEventualConsistency())
+	if err != nil {
+		return fmt.Errorf("DeleteSitePermission: request failed, error: %w", err)
+	}
+
+	return nil
+}
@@ -38,6 +38,10 @@ func (c *Client) ListSites(ctx context.Context, bag *pagination.Bag) ([]Site, er
 		}
 	}
 
+	for _, site := range resp.Value {
+		c.siteWebURLs.Store(site.ID, site.WebUrl)
+	}
+
 	return resp.Value, nil
 }
 
@@ -57,5 +61,53 @@ func (c *Client) GetSiteByID(ctx context.Context, id string) (*Site, error) {
 		return nil, fmt.Errorf("GetSiteByID: request failed, error: %w", err)
 	}
 
+	c.siteWebURLs.Store(resp.ID, resp.WebUrl)
+
+	return &resp, nil
+}
+
+// GetSiteWebURL returns the URL of a site by its ID, sites already seen
+// by ListSites or GetSiteByID are not fetched again.
+func (c *Client) GetSiteWebURL(ctx context.Context, id string) (string, error) {
+	if webURL, ok := c.siteWebURLs.Load(id); ok {
+		return webURL.(string), nil
+	}
+
+	site, err := c.GetSiteByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	return site.WebUrl, nil
+}
+
+// GetSiteByWebURL fetch a site by its URL.
+//
+// Permission required: `Sites.Read.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/site-getbypath
+func (c *Client) GetSiteByWebURL(ctx context.Context, siteWebURL string) (*Site, error) {
+	u, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, fmt.Errorf("GetSiteByWebURL: invalid site URL '%s', error: %w", siteWebURL, err)
+	}
+
+	sitePath := "sites/" + u.Host
+	if serverRelativePath := strings.Trim(u.Path, "/"); serverRelativePath != "" {
+		sitePath += ":/" + serverRelativePath + ":"
+	}
+
+	defaultValues := url.Values{}
+	defaultValues.Set("$select", strings.Join([]string{"id", "name", "displayName", "siteCollection", "webUrl", "root"}, ","))
+
+	targetURL := c.buildURL(sitePath, defaultValues)
+	var resp Site
+
+	err = c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("GetSiteByWebURL: request failed, error: %w", err)
+	}
+
+	c.siteWebURLs.Store(resp.ID, resp.WebUrl)
+
 	return &resp, nil
 }
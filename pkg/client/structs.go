@@ -26,6 +26,93 @@ type SiteCollection struct {
 	Root             *Root  `json:"root"`             // If present, indicates that this is a root site collection in SharePoint. Read-only.
 }
 
+type EntraUser struct {
+	ID                string `json:"id"`                // The unique identifier for the user. Read-only.
+	DisplayName       string `json:"displayName"`       // The name displayed in the address book for the user.
+	Mail              string `json:"mail"`              // The SMTP address for the user.
+	UserPrincipalName string `json:"userPrincipalName"` // The user principal name (UPN) of the user.
+}
+
+type Identity struct {
+	ID          string `json:"id,omitempty"`          // Unique identifier for the identity.
+	DisplayName string `json:"displayName,omitempty"` // The display name of the identity.
+	Email       string `json:"email,omitempty"`       // The email address of the identity, only for users.
+	LoginName   string `json:"loginName,omitempty"`   // The sign in name of the SharePoint identity, only for SharePoint users.
+}
+
+type IdentitySet struct {
+	Application *Identity `json:"application,omitempty"` // Optional. The application associated with this action.
+	User        *Identity `json:"user,omitempty"`        // Optional. The user associated with this action.
+	Group       *Identity `json:"group,omitempty"`       // Optional. The group associated with this action.
+	SiteUser    *Identity `json:"siteUser,omitempty"`    // Optional. The SharePoint user associated with this action.
+	SiteGroup   *Identity `json:"siteGroup,omitempty"`   // Optional. The SharePoint group associated with this action.
+}
+
+type SitePermission struct {
+	ID    string   `json:"id,omitempty"`    // The unique identifier of the permission among all permissions on the item. Read-only.
+	Roles []string `json:"roles,omitempty"` // The type of permission, for example, read.
+	// For site level permissions, the set of identities the permission was granted to.
+	GrantedToIdentitiesV2 []IdentitySet `json:"grantedToIdentitiesV2,omitempty"`
+	// Deprecated in favor of GrantedToIdentitiesV2, still the one used to create site level permissions.
+	GrantedToIdentities []IdentitySet `json:"grantedToIdentities,omitempty"`
+}
+
+// Applications returns the applications the permission was granted to.
+func (p SitePermission) Applications() []Identity {
+	identities := p.GrantedToIdentitiesV2
+	if len(identities) == 0 {
+		identities = p.GrantedToIdentities
+	}
+
+	var ret []Identity
+	for _, identity := range identities {
+		if identity.Application != nil && identity.Application.ID != "" {
+			ret = append(ret, *identity.Application)
+		}
+	}
+
+	return ret
+}
+
+type ItemReference struct {
+	DriveID string `json:"driveId"` // Identifier of the drive instance that contains the item. Read-only.
+}
+
+type DriveItem struct {
+	ID              string        `json:"id"`              // The unique identifier of the item within the Drive. Read-only.
+	Name            string        `json:"name"`            // The name of the item (filename and extension). Read-write.
+	WebUrl          string        `json:"webUrl"`          // URL that displays the resource in the browser. Read-only.
+	ParentReference ItemReference `json:"parentReference"` // Parent information, if the item has a parent. Read-write.
+}
+
+type SharingLink struct {
+	Type             string `json:"type"`             // The type of the link created, i.e. view, edit or embed.
+	Scope            string `json:"scope"`            // The scope of the link, i.e. anonymous, organization or users.
+	WebUrl           string `json:"webUrl"`           // A URL that opens the item in the browser on the OneDrive website.
+	PreventsDownload bool   `json:"preventsDownload"` // If true then the user can only use this link to view the item on the web, and cannot use it to download the contents of the item.
+}
+
+type SharingInvitation struct {
+	Email          string       `json:"email"`          // The email address provided for the recipient of the sharing invitation. Read-only.
+	InvitedBy      *IdentitySet `json:"invitedBy"`      // Provides information about who sent the invitation that created this permission, if that information is available. Read-only.
+	SignInRequired bool         `json:"signInRequired"` // If true the recipient of the invitation needs to sign in in order to access the shared item. Read-only.
+}
+
+type DriveRecipient struct {
+	Email    string `json:"email,omitempty"`    // The email address for the recipient, if the recipient has an associated email address.
+	ObjectID string `json:"objectId,omitempty"` // The unique identifier for the recipient in the directory.
+}
+
+type DrivePermission struct {
+	ID                    string             `json:"id"`                    // The unique identifier of the permission among all permissions on the item. Read-only.
+	Roles                 []string           `json:"roles"`                 // The type of permission, for example, read.
+	Link                  *SharingLink       `json:"link"`                  // Provides the link details of the current permission, if it's a link type permission. Read-only.
+	ExpirationDateTime    string             `json:"expirationDateTime"`    // The date and time when the permission expires, if any.
+	HasPassword           bool               `json:"hasPassword"`           // Indicates whether the password is set for this permission. Read-only.
+	GrantedToIdentitiesV2 []IdentitySet      `json:"grantedToIdentitiesV2"` // For link type permissions, the details of the users to whom permission was granted. Read-only.
+	Invitation            *SharingInvitation `json:"invitation"`            // Details of any associated sharing invitation for this permission. Read-only.
+}
+
 // Local Variables:
 // go-tag-args: ("-transform" "camelcase")
 // End:
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// GetUserByID fetch an Entra user.
+//
+// Permission required: `User.Read.All`
+// documentation: https://learn.microsoft.com/en-us/graph/api/user-get
+func (c *Client) GetUserByID(ctx context.Context, id string) (*EntraUser, error) {
+	defaultValues := url.Values{}
+	defaultValues.Set("$select", strings.Join([]string{"id", "displayName", "mail", "userPrincipalName"}, ","))
+
+	targetURL := c.buildURL(path.Join("users", id), defaultValues)
+	var resp EntraUser
+
+	err := c.query(ctx, makeGraphReadScopes(c.GraphDomain), http.MethodGet, targetURL, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserByID: request failed, error: %w", err)
+	}
+
+	return &resp, nil
+}
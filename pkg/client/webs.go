@@ -0,0 +1,128 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+var webFields = []string{"Id", "Title", "Url", "ServerRelativeUrl", "HasUniqueRoleAssignments"}
+
+// GetSiteCollection fetch the site collection a site belongs to.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499821(v=office.15)#site-properties
+func (c *Client) GetSiteCollection(ctx context.Context, siteWebURL string) (*SharePointSite, error) {
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/site")
+	query := url.Query()
+	query.Set("$select", "Id,Url")
+	url.RawQuery = query.Encode()
+
+	var data SharePointSite
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.GetSiteCollection: %w", err)
+	}
+
+	return &data, nil
+}
+
+// GetWeb fetch a web (a site or a subsite) by its URL.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#web-properties
+func (c *Client) GetWeb(ctx context.Context, webURL string) (*Web, error) {
+	url, err := url.Parse(webURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web")
+	query := url.Query()
+	query.Set("$select", strings.Join(webFields, ","))
+	url.RawQuery = query.Encode()
+
+	var data Web
+	_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+	if err != nil {
+		return nil, fmt.Errorf("Client.GetWeb: %w", err)
+	}
+
+	c.webURLs.Store(data.Id, data.Url)
+
+	return &data, nil
+}
+
+// ListWebs list the webs (subsites) right below a web, their own subsites
+// are not included.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499819(v=office.15)#webcollection-resource
+func (c *Client) ListWebs(ctx context.Context, webURL string) ([]Web, error) {
+	url, err := url.Parse(webURL)
+	if err != nil {
+		return nil, err
+	}
+
+	url.Path = path.Join(url.Path, "_api/web/webs")
+	query := url.Query()
+	query.Set("$select", strings.Join(webFields, ","))
+	url.RawQuery = query.Encode()
+
+	var ret []Web
+	for {
+		var data ListWebsResponse
+		_, err = c.sharePointQuery(ctx, http.MethodGet, url, nil, &data)
+		if err != nil {
+			return nil, fmt.Errorf("Client.ListWebs: %w", err)
+		}
+
+		for _, web := range data.Value {
+			c.webURLs.Store(web.Id, web.Url)
+		}
+		ret = append(ret, data.Value...)
+
+		if data.NextLink == "" {
+			return ret, nil
+		}
+
+		url, err = url.Parse(data.NextLink)
+		if err != nil {
+			return nil, fmt.Errorf("Client.ListWebs: invalid next link '%s', error: %w", data.NextLink, err)
+		}
+	}
+}
+
+// GetWebURL returns the URL of a web of the site collection by its ID,
+// webs already seen by ListWebs or GetWeb are not fetched again.
+//
+// documentation: https://learn.microsoft.com/en-us/previous-versions/office/developer/sharepoint-rest-reference/dn499821(v=office.15)#openwebbyid-method
+func (c *Client) GetWebURL(ctx context.Context, siteWebURL, webID string) (string, error) {
+	if webURL, ok := c.webURLs.Load(webID); ok {
+		return webURL.(string), nil
+	}
+
+	url, err := url.Parse(siteWebURL)
+	if err != nil {
+		return "", err
+	}
+
+	url.Path = path.Join(url.Path, "_api/site/openWebById")
+
+	body := map[string]string{"webId": webID}
+
+	var data Web
+	_, err = c.sharePointQuery(ctx, http.MethodPost, url, body, &data)
+	if err != nil {
+		return "", fmt.Errorf("Client.GetWebURL: %w", err)
+	}
+
+	c.webURLs.Store(webID, data.Url)
+
+	return data.Url, nil
+}
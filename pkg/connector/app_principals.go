@@ -0,0 +1,145 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// appPrincipalBuilder syncs the apps that got access to SharePoint, both
+// SharePoint add-ins and Entra apps show up as `i:0i.t|ms.sp.ext|<app ID>@<tenant ID>`.
+//
+// App principals are listed per site; an app with access to many sites is
+// listed on each of them under the same ID and stored once.
+type appPrincipalBuilder struct {
+	client *client.Client
+	// also list the apps granted access through Sites.Selected
+	syncSiteAppPermissions bool
+}
+
+func (a *appPrincipalBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return appPrincipalResourceType
+}
+
+func (a *appPrincipalBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// app principals are listed per site
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, a.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	securityPrincipals, err := a.client.ListSecurityPrincipals(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("unable to list SharePoint security principals of site '%s', error: %w", siteWebURL, err)
+	}
+
+	// an app can be both a site user and granted access through Sites.Selected
+	seen := make(map[string]struct{})
+
+	var ret []*v2.Resource
+	for _, securityPrincipal := range securityPrincipals {
+		identity, err := claims.Parse(securityPrincipal.LoginName)
+		if err != nil {
+			ctxzap.Extract(ctx).Warn("cannot identify SharePoint security principal, skipping", zap.String("site", siteWebURL), zap.String("principal", securityPrincipal.LoginName), zap.Error(err))
+			continue
+		}
+		if identity.Kind != claims.App {
+			continue
+		}
+		if _, ok := seen[identity.Identifier]; ok {
+			continue
+		}
+
+		rsc, err := convertAppPrincipal2Resource(securityPrincipal, identity)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		seen[identity.Identifier] = struct{}{}
+		ret = append(ret, rsc)
+	}
+
+	if !a.syncSiteAppPermissions {
+		return ret, "", nil, nil
+	}
+
+	siteID, err := siteGraphIDOf(ctx, a.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// apps granted access through Sites.Selected don't have to be site users
+	permissions, err := a.client.ListSitePermissions(ctx, siteID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("appPrincipalBuilder.List: cannot list Sites.Selected permissions of site '%s', error: %w", siteWebURL, err)
+	}
+
+	for _, permission := range permissions {
+		for _, app := range permission.Applications() {
+			if _, ok := seen[app.ID]; ok {
+				continue
+			}
+
+			identity := claims.Identity{Kind: claims.App, Identifier: app.ID, TenantID: a.client.TenantID()}
+			securityPrincipal := client.SecurityPrincipal{
+				Title:     app.DisplayName,
+				LoginName: claims.AppLoginName(app.ID, a.client.TenantID()),
+			}
+
+			rsc, err := convertAppPrincipal2Resource(securityPrincipal, identity)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			seen[app.ID] = struct{}{}
+			ret = append(ret, rsc)
+		}
+	}
+
+	return ret, "", nil, nil
+}
+
+func (a *appPrincipalBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (a *appPrincipalBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newAppPrincipalBuilder(c *client.Client, syncSiteAppPermissions bool) *appPrincipalBuilder {
+	return &appPrincipalBuilder{client: c, syncSiteAppPermissions: syncSiteAppPermissions}
+}
+
+func convertAppPrincipal2Resource(securityPrincipal client.SecurityPrincipal, identity claims.Identity) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"app id":     identity.Identifier,
+		"tenant id":  identity.TenantID,
+		"title":      securityPrincipal.Title,
+		"login name": securityPrincipal.LoginName,
+	}
+
+	displayName := securityPrincipal.Title
+	if displayName == "" {
+		displayName = identity.Identifier
+	}
+
+	rsc, err := resource.NewAppResource(displayName, appPrincipalResourceType, identity.Identifier, []resource.AppTraitOption{
+		resource.WithAppProfile(profile),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resource from app principal '%s', error: %w", securityPrincipal.LoginName, err)
+	}
+
+	return rsc, nil
+}
@@ -0,0 +1,75 @@
+package connector
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+func TestAppPrincipalBuilderList(t *testing.T) {
+	const (
+		addInID    = "00000000-0000-0000-0000-0000000000a1"
+		selectedID = "00000000-0000-0000-0000-0000000000a2"
+	)
+
+	addIn := client.SecurityPrincipal{
+		Id:            10,
+		Title:         "Workflow add-in",
+		LoginName:     claims.AppLoginName(addInID, testTenantID),
+		PrincipalType: client.User,
+	}
+
+	tenant := newFakeTenant()
+	tenant.onSite(testSiteID, testSiteWebURL)
+	tenant.onSite(testFinanceSiteID, testFinanceSiteWebURL)
+	tenant.onSecurityPrincipals(testSiteWebURL, testEntraUser, addIn)
+	tenant.onSecurityPrincipals(testFinanceSiteWebURL, testEntraUser, addIn)
+	tenant.on(http.MethodGet, "https://graph.microsoft.com/v1.0/sites/"+testSiteID+"/permissions", http.StatusOK, map[string]any{"value": []client.SitePermission{}})
+	tenant.on(http.MethodGet, "https://graph.microsoft.com/v1.0/sites/"+testFinanceSiteID+"/permissions", http.StatusOK, map[string]any{"value": []client.SitePermission{
+		{
+			ID:    "aTowaS50fG1zLnNwLmV4dHw",
+			Roles: []string{client.SitePermissionRead},
+			GrantedToIdentitiesV2: []client.IdentitySet{
+				{Application: &client.Identity{ID: addInID, DisplayName: "Workflow add-in"}},
+				{Application: &client.Identity{ID: selectedID, DisplayName: "Reporting app"}},
+			},
+		},
+	}})
+
+	testCases := []struct {
+		name                   string
+		syncSiteAppPermissions bool
+		want                   [][]string
+	}{
+		{
+			name: "site users",
+			want: [][]string{{addInID}, {addInID}},
+		},
+		{
+			// apps granted Sites.Selected permissions are listed once per site
+			name:                   "site users and Sites.Selected permissions",
+			syncSiteAppPermissions: true,
+			want:                   [][]string{{addInID}, {addInID, selectedID}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			builder := newAppPrincipalBuilder(newTestClient(t, tenant), tc.syncSiteAppPermissions)
+
+			// app principals are keyed by app ID
+			got := listTenantWide(t, builder, testSiteID, testFinanceSiteID)
+			if !slices.EqualFunc(got, tc.want, slices.Equal) {
+				t.Errorf("got app principals %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	// only the permissions of the listed site are fetched
+	if got := tenant.requested(http.MethodGet, "https://graph.microsoft.com/v1.0/sites/"+testFinanceSiteID+"/permissions"); got != 1 {
+		t.Errorf("got %d requests for the Sites.Selected permissions of the finance site, want 1", got)
+	}
+}
@@ -12,15 +12,27 @@ import (
 )
 
 type Connector struct {
-	client *client.Client
+	client                 *client.Client
+	syncSiteAppPermissions bool
+	syncHiddenLists        bool
+	itemScanner            *itemScanner
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
 	return []connectorbuilder.ResourceSyncer{
-		newSiteBuilder(d.client),
+		newSiteBuilder(d.client, d.syncSiteAppPermissions),
+		newWebBuilder(d.client),
+		newListBuilder(d.client, d.syncHiddenLists),
+		newFolderBuilder(d.client, d.itemScanner),
+		newFileBuilder(d.client, d.itemScanner),
+		newSharingLinkBuilder(d.client),
 		newGroupBuilder(d.client),
 		newSecurityPrincipalBuilder(d.client),
+		newSiteUserBuilder(d.client),
+		newAppPrincipalBuilder(d.client, d.syncSiteAppPermissions),
+		newRoleDefinitionBuilder(d.client),
+		newTenantAudienceBuilder(),
 	}
 }
 
@@ -45,14 +57,19 @@ func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, erro
 }
 
 // New returns a new instance of the connector.
-// cert parameter should contain the raw content of a PFX certificate file.
-func New(ctx context.Context, tenantID, clientID, clientSecret, graphDomain, sharepointDomain, cert string,
-	certpassword string, syncSharePointHomeOrgLinks bool,
+func New(ctx context.Context, cloud client.Cloud, tenantID, clientID, clientSecret, graphDomain, sharepointDomain string,
+	cert *client.Certificate, federatedTokenFile string, syncSharePointHomeOrgLinks, syncSiteAppPermissions, syncHiddenLists bool,
+	itemScanLibraries []LibraryScan, itemScanLimit int,
 ) (*Connector, error) {
-	c, err := client.New(ctx, tenantID, clientID, clientSecret, graphDomain, sharepointDomain, cert, certpassword, syncSharePointHomeOrgLinks)
+	c, err := client.New(ctx, cloud, tenantID, clientID, clientSecret, graphDomain, sharepointDomain, cert, federatedTokenFile, syncSharePointHomeOrgLinks)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make connector, error: %w", err)
 	}
 
-	return &Connector{client: c}, nil
+	return &Connector{
+		client:                 c,
+		syncSiteAppPermissions: syncSiteAppPermissions,
+		syncHiddenLists:        syncHiddenLists,
+		itemScanner:            newItemScanner(c, itemScanLibraries, itemScanLimit),
+	}, nil
 }
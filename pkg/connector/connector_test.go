@@ -0,0 +1,233 @@
+package connector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+const (
+	testTenantID   = "00000000-0000-0000-0000-00000000000a"
+	testClientID   = "00000000-0000-0000-0000-00000000000b"
+	testSiteWebURL = "https://contoso.sharepoint.com/sites/hr"
+)
+
+// fakeTenant is a stand-in for Microsoft Graph and SharePoint: it answers
+// the requests of the client with the responses registered with on and
+// records every request it gets.
+type fakeTenant struct {
+	mtx       sync.Mutex
+	responses map[string]fakeResponse
+	requests  []string
+}
+
+type fakeResponse struct {
+	status int
+	body   any
+}
+
+func newFakeTenant() *fakeTenant {
+	return &fakeTenant{responses: map[string]fakeResponse{}}
+}
+
+// on registers the response to the request made with method to rawURL.
+// The query string of requests only matters when rawURL has one.
+func (f *fakeTenant) on(method, rawURL string, status int, body any) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	req := httptest.NewRequest(method, rawURL, nil)
+	key := requestKey(req)
+	if req.URL.RawQuery != "" {
+		key += "?" + req.URL.RawQuery
+	}
+	f.responses[key] = fakeResponse{status: status, body: body}
+}
+
+// requested tells how many times method was sent to rawURL.
+func (f *fakeTenant) requested(method, rawURL string) int {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	key := requestKey(httptest.NewRequest(method, rawURL, nil))
+
+	count := 0
+	for _, request := range f.requests {
+		if request == key {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (f *fakeTenant) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+		_ = req.Body.Close()
+	}
+
+	f.mtx.Lock()
+	key := requestKey(req)
+	f.requests = append(f.requests, key)
+	response, ok := f.responses[key+"?"+req.URL.RawQuery]
+	if !ok {
+		response, ok = f.responses[key]
+	}
+	f.mtx.Unlock()
+
+	if !ok {
+		response = fakeResponse{
+			status: http.StatusNotFound,
+			body:   map[string]any{"error": map[string]string{"code": "itemNotFound", "message": "no response for " + key}},
+		}
+	}
+
+	w := httptest.NewRecorder()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.status)
+	if response.body != nil {
+		_ = json.NewEncoder(w).Encode(response.body)
+	}
+
+	resp := w.Result()
+	resp.Request = req
+
+	return resp, nil
+}
+
+func requestKey(req *http.Request) string {
+	return fmt.Sprintf("%s %s%s", req.Method, req.URL.Host, req.URL.Path)
+}
+
+// newTestCertificate makes a self-signed certificate for the client to
+// authenticate to the fake tenant with.
+func newTestCertificate(t *testing.T) *client.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "baton-sharepoint"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &client.Certificate{Chain: []*x509.Certificate{cert}, Key: key}
+}
+
+// onLogin registers the Entra endpoints the client gets its tokens from.
+func (f *fakeTenant) onLogin() {
+	const authority = "https://login.microsoftonline.com/" + testTenantID
+
+	f.on(http.MethodGet, "https://login.microsoftonline.com/common/discovery/instance", http.StatusOK, map[string]any{
+		"tenant_discovery_endpoint": authority + "/v2.0/.well-known/openid-configuration",
+		"api-version":               "1.1",
+		"metadata": []map[string]any{{
+			"preferred_network": "login.microsoftonline.com",
+			"preferred_cache":   "login.windows.net",
+			"aliases":           []string{"login.microsoftonline.com", "login.windows.net"},
+		}},
+	})
+	f.on(http.MethodGet, authority+"/v2.0/.well-known/openid-configuration", http.StatusOK, map[string]any{
+		"authorization_endpoint": authority + "/oauth2/v2.0/authorize",
+		"token_endpoint":         authority + "/oauth2/v2.0/token",
+		"issuer":                 authority + "/v2.0",
+	})
+	f.on(http.MethodPost, authority+"/oauth2/v2.0/token", http.StatusOK, map[string]any{
+		"token_type":   "Bearer",
+		"access_token": "token",
+		"expires_in":   3600,
+	})
+}
+
+// newTestClient makes a client that talks to the fake tenant.
+func newTestClient(t *testing.T, tenant *fakeTenant) *client.Client {
+	t.Helper()
+
+	// responses change as the tests provision, they must not be cached
+	t.Setenv("BATON_DISABLE_HTTP_CACHE", "true")
+
+	tenant.onLogin()
+	c, err := client.New(context.Background(), client.CloudPublic, testTenantID, testClientID, "", "", "contoso", newTestCertificate(t), "", false,
+		client.WithHTTPClient(&http.Client{Transport: tenant}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// onSite registers the Microsoft Graph site siteID, with its URL.
+func (f *fakeTenant) onSite(siteID, siteWebURL string) {
+	f.on(http.MethodGet, "https://graph.microsoft.com/v1.0/sites/"+siteID, http.StatusOK, client.Site{ID: siteID, WebUrl: siteWebURL})
+}
+
+type resourceLister interface {
+	List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error)
+}
+
+// listTenantWide lists the resources of the builder on each site, in a
+// single page, and checks they have no parent: they are keyed tenant-wide
+// so the same resource listed on many sites is stored once.
+func listTenantWide(t *testing.T, builder resourceLister, siteIDs ...string) [][]string {
+	t.Helper()
+
+	var ret [][]string
+	for _, siteID := range siteIDs {
+		resources, npt, _, err := builder.List(context.Background(), &v2.ResourceId{ResourceType: siteResourceType.Id, Resource: siteID}, &pagination.Token{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if npt != "" {
+			t.Errorf("got next page token %q on site '%s', want none", npt, siteID)
+		}
+		for _, rsc := range resources {
+			if rsc.ParentResourceId != nil {
+				t.Errorf("got parent '%s' for '%s', want none", rsc.ParentResourceId.Resource, rsc.Id.Resource)
+			}
+		}
+		ret = append(ret, resourceIDs(resources))
+	}
+
+	return ret
+}
+
+func resourceIDs(resources []*v2.Resource) []string {
+	ret := make([]string, 0, len(resources))
+	for _, rsc := range resources {
+		ret = append(ret, rsc.Id.Resource)
+	}
+
+	return ret
+}
@@ -3,6 +3,7 @@ package connector
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -11,7 +12,11 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
 	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
 	"github.com/conductorone/baton-sharepoint/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 const (
@@ -19,6 +24,14 @@ const (
 	resourceTypeGroup = "group"
 	// resourceTypeUser represents the user resource type.
 	resourceTypeUser = "user"
+
+	// entraGroupMembersEntitlement and entraGroupOwnersEntitlement are the
+	// slugs of the entitlements of Entra groups, as synced by baton-microsoft-entra.
+	entraGroupMembersEntitlement = "members"
+	entraGroupOwnersEntitlement  = "owners"
+
+	// groupMemberEntitlement is the slug of the membership entitlement of every SharePoint group.
+	groupMemberEntitlement = "member"
 )
 
 type groupBuilder struct {
@@ -30,79 +43,62 @@ func (g *groupBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
 }
 
 func (g *groupBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	bag := &pagination.Bag{}
-	err := bag.Unmarshal(pToken.Token)
+	// SharePoint groups are listed per site
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, g.client, parentResourceID.Resource)
 	if err != nil {
 		return nil, "", nil, err
 	}
-	if bag.Current() != nil {
-		bag.Push(pagination.PageState{ResourceTypeID: groupResourceType.Id})
+
+	groups, err := g.client.ListGroupsForSite(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("unable to list SharePoint groups of site '%s', error: %w", siteWebURL, err)
 	}
 
-	sites, err := g.client.ListSites(ctx, bag)
+	web, err := g.client.GetWebWithAssociatedGroups(ctx, siteWebURL)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("unable to list SharePoint groups, error: %w", err)
+		return nil, "", nil, fmt.Errorf("unable to find the associated groups of site '%s', error: %w", siteWebURL, err)
 	}
 
 	var ret []*v2.Resource
-
-	for _, site := range sites {
-		groups, err := g.client.ListGroupsForSite(ctx, site.WebUrl)
+	for _, group := range groups {
+		g, err := convertGroup2Resource(parentResourceID, siteWebURL, associatedRoleOf(web, group.Id), group)
 		if err != nil {
 			return nil, "", nil, err
 		}
-
-		for _, group := range groups {
-			siteID, err := resource.NewResourceID(siteResourceType, site.WebUrl)
-			if err != nil {
-				return nil, "", nil, err
-			}
-			g, err := resource.NewGroupResource(group.Title, groupResourceType, group.ODataID, []resource.GroupTraitOption{
-				resource.WithGroupProfile(map[string]interface{}{
-					"site":     site.DisplayName,
-					"site url": site.WebUrl,
-					"id":       group.Id,
-				}),
-			}, resource.WithParentResourceID(siteID))
-			if err != nil {
-				return nil, "", nil, fmt.Errorf("cannot create resource from SharePoint group, err: %w", err)
-			}
-			ret = append(ret, g)
-		}
-	}
-
-	ntp, err := bag.Marshal()
-	if err != nil {
-		return nil, "", nil, err
+		ret = append(ret, g)
 	}
 
-	return ret, ntp, nil, nil
+	return ret, "", nil, nil
 }
 
 func (g *groupBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	parts := strings.Split(strings.ToLower(resource.DisplayName), " ")
-	kind := strings.TrimSuffix(parts[len(parts)-1], "s") // make the kind singular
-
 	opts := []entitlement.EntitlementOption{
 		entitlement.WithDisplayName(fmt.Sprintf("Membership to %s", resource.DisplayName)),
 	}
 
-	ent := entitlement.NewAssignmentEntitlement(resource, kind, opts...)
+	ent := entitlement.NewAssignmentEntitlement(resource, groupMemberEntitlement, opts...)
 
 	return []*v2.Entitlement{ent}, "", nil, nil
 }
 
 func (g *groupBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	securityPrincipals, err := g.client.ListSecurityPrincipalsInGroupByGroupID(ctx, rsc.Id.Resource)
+	siteWebURL, groupID, err := g.groupOf(ctx, rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("groupBuilder.Grants: %w", err)
+	}
+
+	securityPrincipals, err := g.client.ListSecurityPrincipalsInGroupByGroupID(ctx, siteWebURL, groupID)
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	parts := strings.Split(strings.ToLower(rsc.DisplayName), " ")
-	kind := strings.TrimSuffix(parts[len(parts)-1], "s")
 	var ret []*v2.Grant
 	for _, securityPrincipal := range securityPrincipals {
-		granted, isGrantable, err := grantHelper(ctx, securityPrincipal, kind, rsc)
+		granted, isGrantable, err := grantHelper(ctx, securityPrincipal, groupMemberEntitlement, rsc)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("groupBuilder.Grants: failed to grant entitlement, error: %w", err)
 		}
@@ -112,64 +108,334 @@ func (g *groupBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pag
 		ret = append(ret, granted)
 	}
 
-	return ret, "", nil, nil
+	return mergeGrants(ret), "", nil, nil
 }
 
-func grantHelper(ctx context.Context, securityPrincipal client.SecurityPrincipal, kind string, rsc *v2.Resource) (*v2.Grant, bool, error) {
-	// Filter out built ins
+func (g *groupBuilder) Grant(ctx context.Context, principal *v2.Resource, ent *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	siteWebURL, groupID, err := g.groupOf(ctx, ent.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Grant: %w", err)
+	}
 
-	if securityPrincipal.LoginName == "SHAREPOINT\\system" {
-		return nil, false, nil
+	loginName, err := loginNameForPrincipal(ctx, g.client, principal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Grant: %w", err)
 	}
-	if strings.Contains(securityPrincipal.LoginName, "|rolemanager|") {
-		return nil, false, nil
+
+	members, err := g.client.ListSecurityPrincipalsInGroupByGroupID(ctx, siteWebURL, groupID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Grant: cannot list members of group, error: %w", err)
+	}
+	if _, found := findSecurityPrincipal(members, loginName); found {
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
 	}
-	// Baton ID
-	var resourceType string
-	var principalName string
-	var keyName string
 
-	switch {
-	case strings.Contains(securityPrincipal.LoginName, "federateddirectoryclaimprovider"):
-		resourceType = resourceTypeGroup // the type of resource on Entra
-		parts := strings.Split(securityPrincipal.LoginName, "|")
-		if len(parts) < 3 {
-			return nil, false, fmt.Errorf("cannot identify group by its ID, error: malformed login name '%s'", securityPrincipal.LoginName)
-		}
-
-		principalName = strings.TrimSuffix(parts[2], "_o") // remove suffix in Entra's group ID. Used by SharePoint to indicate "Owners"
-	case strings.Contains(securityPrincipal.LoginName, "|tenant|"):
-		resourceType = resourceTypeGroup
-		parts := strings.Split(securityPrincipal.LoginName, "|")
-		if len(parts) < 3 {
-			return nil, false, fmt.Errorf("cannot identify group by its ID, error: malformed login name '%s'", securityPrincipal.LoginName)
-		}
-		principalName = parts[2]
-		keyName = "loginName"
-	default:
-		resourceType = resourceTypeUser
-		principalName = securityPrincipal.UserPrincipalName
-		keyName = "userPrincipalName"
+	user, err := g.client.EnsureUser(ctx, siteWebURL, loginName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Grant: cannot add '%s' to site, error: %w", loginName, err)
 	}
 
-	principal := &v2.ResourceId{
-		ResourceType: resourceType,
-		Resource:     principalName,
+	err = g.client.AddSecurityPrincipalToGroup(ctx, siteWebURL, groupID, user.LoginName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Grant: cannot add '%s' to group, error: %w", loginName, err)
 	}
 
-	if resourceType == resourceTypeGroup {
-		return grant.NewGrant(rsc, kind, principal, grant.WithAnnotation(&v2.ExternalResourceMatchID{
-			Id: principalName,
-		})), true, nil
-	} else {
+	return []*v2.Grant{grant.NewGrant(ent.Resource, ent.Slug, principal.Id)}, nil, nil
+}
+
+func (g *groupBuilder) Revoke(ctx context.Context, toRevoke *v2.Grant) (annotations.Annotations, error) {
+	siteWebURL, groupID, err := g.groupOf(ctx, toRevoke.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Revoke: %w", err)
+	}
+
+	loginName, err := loginNameForPrincipal(ctx, g.client, toRevoke.Principal)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Revoke: %w", err)
+	}
+
+	members, err := g.client.ListSecurityPrincipalsInGroupByGroupID(ctx, siteWebURL, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Revoke: cannot list members of group, error: %w", err)
+	}
+	member, found := findSecurityPrincipal(members, loginName)
+	if !found {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	// remove the member by the login name SharePoint knows it by
+	err = g.client.RemoveSecurityPrincipalFromGroup(ctx, siteWebURL, groupID, member.LoginName)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Revoke: cannot remove '%s' from group, error: %w", member.LoginName, err)
+	}
+
+	return nil, nil
+}
+
+// Create makes a SharePoint group on the parent site of the resource. The
+// group trait profile may set the membership settings, the owner (a login
+// name or a user principal name) and the ID of a permission level the
+// group is given on the site.
+func (g *groupBuilder) Create(ctx context.Context, rsc *v2.Resource) (*v2.Resource, annotations.Annotations, error) {
+	if rsc.ParentResourceId == nil || rsc.ParentResourceId.ResourceType != siteResourceType.Id {
+		return nil, nil, fmt.Errorf("groupBuilder.Create: SharePoint groups must be created on a site")
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, g.client, rsc.ParentResourceId.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Create: %w", err)
+	}
+
+	var profile *structpb.Struct
+	if trait, err := resource.GetGroupTrait(rsc); err == nil {
+		profile = trait.GetProfile()
+	}
+
+	creation := client.SharePointSiteGroupCreation{
+		Title:                          rsc.DisplayName,
+		Description:                    rsc.Description,
+		AllowMembersEditMembership:     profile.GetFields()["allow members edit membership"].GetBoolValue(),
+		AllowRequestToJoinLeave:        profile.GetFields()["allow request to join leave"].GetBoolValue(),
+		AutoAcceptRequestToJoinLeave:   profile.GetFields()["auto accept request to join leave"].GetBoolValue(),
+		OnlyAllowMembersViewMembership: profile.GetFields()["only allow members view membership"].GetBoolValue(),
+	}
+	if description, ok := resource.GetProfileStringValue(profile, "description"); ok {
+		creation.Description = description
+	}
+	if email, ok := resource.GetProfileStringValue(profile, "request to join leave email setting"); ok {
+		creation.RequestToJoinLeaveEmailSetting = email
+	}
+	if creation.Title == "" {
+		return nil, nil, fmt.Errorf("groupBuilder.Create: the title of the group is missing")
+	}
+
+	group, err := g.client.CreateGroup(ctx, siteWebURL, creation)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Create: cannot create group '%s', error: %w", creation.Title, err)
+	}
+
+	// a group left half set up would get in the way of a retry
+	err = g.setUpGroup(ctx, siteWebURL, group, profile)
+	if err != nil {
+		if deleteErr := g.client.DeleteGroup(ctx, siteWebURL, group.Id); deleteErr != nil {
+			return nil, nil, fmt.Errorf("groupBuilder.Create: %w, and group '%s' cannot be deleted, error: %w", err, group.Title, deleteErr)
+		}
+		return nil, nil, fmt.Errorf("groupBuilder.Create: %w", err)
+	}
+
+	ret, err := convertGroup2Resource(rsc.ParentResourceId, siteWebURL, "", *group)
+	if err != nil {
+		return nil, nil, fmt.Errorf("groupBuilder.Create: %w", err)
+	}
+
+	return ret, nil, nil
+}
+
+// setUpGroup gives a group created by Create the owner and the permission
+// level of its profile.
+func (g *groupBuilder) setUpGroup(ctx context.Context, siteWebURL string, group *client.SharePointSiteGroup, profile *structpb.Struct) error {
+	if owner, ok := resource.GetProfileStringValue(profile, "owner"); ok && owner != "" {
+		loginName := owner
+		if !strings.Contains(owner, "|") { // a user principal name
+			loginName = claims.UserLoginName(owner)
+		}
+
+		user, err := g.client.EnsureUser(ctx, siteWebURL, loginName)
+		if err != nil {
+			return fmt.Errorf("cannot add owner '%s' to site, error: %w", owner, err)
+		}
+
+		err = g.client.SetGroupOwner(ctx, siteWebURL, group.Id, user.Id)
+		if err != nil {
+			return fmt.Errorf("cannot make '%s' owner of group '%s', error: %w", owner, group.Title, err)
+		}
+	}
+
+	if roleDefinitionID, ok := resource.GetProfileInt64Value(profile, "permission level"); ok && roleDefinitionID != 0 {
+		err := g.client.AddRoleAssignment(ctx, siteWebURL, group.Id, int(roleDefinitionID))
+		if err != nil {
+			return fmt.Errorf("cannot give permission level %d to group '%s', error: %w", roleDefinitionID, group.Title, err)
+		}
+	}
+
+	return nil
+}
+
+func (g *groupBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	siteWebURL, groupID, err := g.groupOf(ctx, resourceId.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Delete: %w", err)
+	}
+
+	err = g.client.DeleteGroup(ctx, siteWebURL, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("groupBuilder.Delete: cannot delete group %d of site '%s', error: %w", groupID, siteWebURL, err)
+	}
+
+	return nil, nil
+}
+
+// groupOf returns the URL of the site and the numeric ID of the group
+// identified by the resource ID.
+func (g *groupBuilder) groupOf(ctx context.Context, resourceID string) (string, int, error) {
+	siteID, groupID, err := parseSharePointGroupResourceID(resourceID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, g.client, siteID)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return siteWebURL, groupID, nil
+}
+
+func grantHelper(ctx context.Context, securityPrincipal client.SecurityPrincipal, kind string, rsc *v2.Resource) (*v2.Grant, bool, error) {
+	identity, err := claims.Parse(securityPrincipal.LoginName)
+	if err != nil {
+		// an odd login name must not stop the rest of the site from syncing
+		ctxzap.Extract(ctx).Warn("cannot identify security principal, skipping", zap.String("principal", securityPrincipal.LoginName), zap.Error(err))
+		return nil, false, nil
+	}
+
+	// broad access claims are granted to their tenant audience
+	if audience, ok := tenantAudienceOf(identity); ok {
+		principal := &v2.ResourceId{
+			ResourceType: tenantAudienceResourceType.Id,
+			Resource:     audience.id,
+		}
+		return grant.NewGrant(rsc, kind, principal), true, nil
+	}
+
+	switch {
+	case identity.Kind == claims.System, identity.Kind == claims.RoleManager: // Filter out built ins
+		return nil, false, nil
+	case identity.IsEntraGroup():
+		// the type of resource on Entra
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeGroup,
+			Resource:     identity.Identifier,
+		}
+
+		// entitlement of the Entra group the grant is expanded to
+		entraEntitlement := entraGroupMembersEntitlement
+		if identity.Kind == claims.M365GroupOwners {
+			entraEntitlement = entraGroupOwnersEntitlement
+		}
+
+		return grant.NewGrant(rsc, kind, principal, grant.WithAnnotation(
+			&v2.ExternalResourceMatchID{
+				Id: identity.Identifier,
+			},
+			&v2.GrantExpandable{
+				EntitlementIds: []string{entitlement.NewEntitlementID(&v2.Resource{Id: principal}, entraEntitlement)},
+			},
+		)), true, nil
+	case securityPrincipal.PrincipalType == client.SecurityGroup:
+		// on-premises security groups are synced as security_principal resources
+		principal := &v2.ResourceId{
+			ResourceType: securityPrincipalResourceType.Id,
+			Resource:     securityPrincipal.LoginName,
+		}
+		return grant.NewGrant(rsc, kind, principal), true, nil
+	case identity.Kind == claims.App:
+		// add-ins and Entra apps are synced as app_principal resources
+		principal := &v2.ResourceId{
+			ResourceType: appPrincipalResourceType.Id,
+			Resource:     identity.Identifier,
+		}
+		return grant.NewGrant(rsc, kind, principal), true, nil
+	case !isEntraUser(securityPrincipal, identity):
+		// users only SharePoint knows about are synced as site_user resources
+		principal := &v2.ResourceId{
+			ResourceType: siteUserResourceType.Id,
+			Resource:     securityPrincipal.LoginName,
+		}
+		return grant.NewGrant(rsc, kind, principal), true, nil
+	default:
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeUser,
+			Resource:     securityPrincipal.UserPrincipalName,
+		}
 		return grant.NewGrant(rsc, kind, principal, grant.WithAnnotation(&v2.ExternalResourceMatch{
-			Key:          keyName,
-			Value:        principalName,
+			Key:          "userPrincipalName",
+			Value:        securityPrincipal.UserPrincipalName,
 			ResourceType: v2.ResourceType_TRAIT_USER,
 		})), true, nil
 	}
 }
 
+// mergeGrants merges the grants that have the same ID. That happens when
+// both the owners and the members of a Microsoft 365 group got the same
+// entitlement, the merged grant is expanded to both Entra entitlements.
+func mergeGrants(grants []*v2.Grant) []*v2.Grant {
+	var ret []*v2.Grant
+	seen := make(map[string]*v2.Grant, len(grants))
+
+	for _, g := range grants {
+		prev, ok := seen[g.Id]
+		if !ok {
+			seen[g.Id] = g
+			ret = append(ret, g)
+			continue
+		}
+
+		prevAnnos := annotations.Annotations(prev.Annotations)
+		prevExpandable := &v2.GrantExpandable{}
+		if ok, err := prevAnnos.Pick(prevExpandable); err != nil || !ok {
+			continue
+		}
+
+		annos := annotations.Annotations(g.Annotations)
+		expandable := &v2.GrantExpandable{}
+		if ok, err := annos.Pick(expandable); err != nil || !ok {
+			continue
+		}
+
+		for _, id := range expandable.EntitlementIds {
+			if !slices.Contains(prevExpandable.EntitlementIds, id) {
+				prevExpandable.EntitlementIds = append(prevExpandable.EntitlementIds, id)
+			}
+		}
+		prevAnnos.Update(prevExpandable)
+		prev.Annotations = prevAnnos
+	}
+
+	return ret
+}
+
+// associatedRoleOf tells if the group is the associated owner, member or
+// visitor group of the site, an empty string is returned otherwise.
+func associatedRoleOf(web *client.Web, groupID int) string {
+	switch {
+	case web.AssociatedOwnerGroup != nil && web.AssociatedOwnerGroup.Id == groupID:
+		return "owner"
+	case web.AssociatedMemberGroup != nil && web.AssociatedMemberGroup.Id == groupID:
+		return "member"
+	case web.AssociatedVisitorGroup != nil && web.AssociatedVisitorGroup.Id == groupID:
+		return "visitor"
+	default:
+		return ""
+	}
+}
+
+func convertGroup2Resource(siteID *v2.ResourceId, siteWebURL, associatedRole string, group client.SharePointSiteGroup) (*v2.Resource, error) {
+	rsc, err := resource.NewGroupResource(group.Title, groupResourceType, sharePointGroupResourceID(siteID.Resource, group.Id), []resource.GroupTraitOption{
+		resource.WithGroupProfile(map[string]interface{}{
+			"site url":           siteWebURL,
+			"id":                 group.Id,
+			"associated role":    associatedRole,
+			"legacy resource id": legacySharePointGroupResourceID(siteWebURL, group.Id),
+		}),
+	}, resource.WithParentResourceID(siteID))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resource from SharePoint group, err: %w", err)
+	}
+
+	return rsc, nil
+}
+
 func newGroupBuilder(c *client.Client) *groupBuilder {
 	return &groupBuilder{client: c}
 }
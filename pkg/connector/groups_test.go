@@ -0,0 +1,73 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+func TestGroupBuilderCreate(t *testing.T) {
+	const (
+		groupID        = 42
+		roleDefinition = 1073741827
+	)
+
+	createURL := testSiteWebURL + "/_api/web/sitegroups"
+	roleAssignmentURL := testSiteWebURL + "/_api/web/roleassignments/addroleassignment(principalid=42,roledefid=1073741827)"
+	deleteURL := testSiteWebURL + "/_api/web/sitegroups/removebyid(42)"
+
+	testCases := []struct {
+		name           string
+		roleAssignment int
+		wantErr        bool
+		wantDeleted    bool
+	}{
+		{
+			name:           "created",
+			roleAssignment: http.StatusOK,
+		},
+		{
+			// the group is deleted so that it can be created again
+			name:           "permission level failure",
+			roleAssignment: http.StatusInternalServerError,
+			wantErr:        true,
+			wantDeleted:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenant := newFakeTenant()
+			tenant.onSite(testSiteID, testSiteWebURL)
+			tenant.on(http.MethodPost, createURL, http.StatusOK, client.SharePointSiteGroup{Id: groupID, Title: "Auditors"})
+			tenant.on(http.MethodPost, roleAssignmentURL, tc.roleAssignment, nil)
+			tenant.on(http.MethodPost, deleteURL, http.StatusOK, nil)
+
+			rsc, err := resource.NewGroupResource("Auditors", groupResourceType, "",
+				[]resource.GroupTraitOption{resource.WithGroupProfile(map[string]any{"permission level": roleDefinition})},
+				resource.WithParentResourceID(&v2.ResourceId{ResourceType: siteResourceType.Id, Resource: testSiteID}),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			builder := newGroupBuilder(newTestClient(t, tenant))
+
+			created, _, err := builder.Create(context.Background(), rsc)
+			if gotErr := err != nil; gotErr != tc.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tc.wantErr)
+			}
+			if !tc.wantErr && created.Id.Resource != sharePointGroupResourceID(testSiteID, groupID) {
+				t.Errorf("got group '%s', want '%s'", created.Id.Resource, sharePointGroupResourceID(testSiteID, groupID))
+			}
+			if deleted := tenant.requested(http.MethodPost, deleteURL) == 1; deleted != tc.wantDeleted {
+				t.Errorf("got group deleted %t, want %t", deleted, tc.wantDeleted)
+			}
+		})
+	}
+}
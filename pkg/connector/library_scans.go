@@ -0,0 +1,143 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// defaultItemScanLimit is the number of items scanned per library when no limit is set.
+const defaultItemScanLimit = 5000
+
+// itemScanPageSize is the number of items requested per page, SharePoint
+// refuses queries over 5000 items (the list view threshold).
+const itemScanPageSize = 500
+
+// LibraryScan is a document library whose files and folders are scanned
+// for unique permissions, as `<site URL>|<library title>`.
+type LibraryScan struct {
+	SiteURL      string
+	LibraryTitle string
+}
+
+// ParseLibraryScan parses a library to scan, i.e.
+// `https://contoso.sharepoint.com/sites/hr|HR Confidential`.
+func ParseLibraryScan(value string) (LibraryScan, error) {
+	siteURL, libraryTitle, found := strings.Cut(value, "|")
+	if !found || libraryTitle == "" {
+		return LibraryScan{}, fmt.Errorf("library '%s' must be given as '<site URL>|<library title>'", value)
+	}
+
+	u, err := url.Parse(siteURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return LibraryScan{}, fmt.Errorf("library '%s' has an invalid site URL '%s'", value, siteURL)
+	}
+
+	return LibraryScan{SiteURL: strings.TrimSuffix(siteURL, "/"), LibraryTitle: libraryTitle}, nil
+}
+
+// itemScanner scans the configured libraries for files and folders with
+// unique permissions, a page at a time. The libraries are scanned once, by
+// the folder builder.
+type itemScanner struct {
+	client    *client.Client
+	libraries []LibraryScan
+	// hard cap on the items scanned per library
+	limit int
+}
+
+// itemScanPage is where the scan of a library stands, it's kept in the
+// page token between the pages of the scan.
+type itemScanPage struct {
+	ListID   string `json:"listId"`
+	NextLink string `json:"nextLink,omitempty"`
+	Scanned  int    `json:"scanned"`
+}
+
+// librariesOf returns the libraries of the site or web that must be scanned.
+func (s *itemScanner) librariesOf(webURL string) []LibraryScan {
+	var ret []LibraryScan
+	for _, library := range s.libraries {
+		if strings.EqualFold(library.SiteURL, strings.TrimSuffix(webURL, "/")) {
+			ret = append(ret, library)
+		}
+	}
+
+	return ret
+}
+
+// scanPage scans a page of the library, pageToken is empty for the first
+// page. The ID of the library and its files and folders with unique
+// permissions in the page are returned, along with the token of the next
+// page, empty once the whole library (or the limit of items) was scanned.
+func (s *itemScanner) scanPage(ctx context.Context, library LibraryScan, pageToken string) (string, []client.ListItem, string, error) {
+	var page itemScanPage
+	if pageToken != "" {
+		if err := json.Unmarshal([]byte(pageToken), &page); err != nil {
+			return "", nil, "", fmt.Errorf("invalid page token of library '%s', error: %w", library.LibraryTitle, err)
+		}
+	}
+
+	if page.ListID == "" {
+		list, err := s.client.GetListByTitle(ctx, library.SiteURL, library.LibraryTitle)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("cannot find library '%s' of site '%s', error: %w", library.LibraryTitle, library.SiteURL, err)
+		}
+		page.ListID = list.Id
+	}
+
+	top := min(itemScanPageSize, s.limit-page.Scanned)
+	items, nextLink, err := s.client.ListListItems(ctx, library.SiteURL, page.ListID, page.NextLink, top)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("cannot list items of library '%s' of site '%s', error: %w", library.LibraryTitle, library.SiteURL, err)
+	}
+
+	// next links keep the $top of the first page, the items over the limit are left out
+	if remaining := s.limit - page.Scanned; len(items) > remaining {
+		items = items[:remaining]
+	}
+
+	var ret []client.ListItem
+	for _, item := range items {
+		if item.HasUniqueRoleAssignments {
+			ret = append(ret, item)
+		}
+	}
+
+	if nextLink == "" {
+		return page.ListID, ret, "", nil
+	}
+
+	page.Scanned += len(items)
+	if page.Scanned >= s.limit {
+		ctxzap.Extract(ctx).Warn("item scan limit reached, the rest of the library was not scanned",
+			zap.String("site", library.SiteURL),
+			zap.String("library", library.LibraryTitle),
+			zap.Int("limit", s.limit),
+		)
+		return page.ListID, ret, "", nil
+	}
+
+	page.NextLink = nextLink
+	nextPageToken, err := json.Marshal(page)
+	if err != nil {
+		return "", nil, "", err
+	}
+
+	return page.ListID, ret, string(nextPageToken), nil
+}
+
+func newItemScanner(c *client.Client, libraries []LibraryScan, limit int) *itemScanner {
+	if limit <= 0 {
+		limit = defaultItemScanLimit
+	}
+
+	return &itemScanner{client: c, libraries: libraries, limit: limit}
+}
@@ -0,0 +1,61 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+func TestItemScannerLimit(t *testing.T) {
+	const libraryID = "9a8b7c6d-5e4f-4a3b-2c1d-0e9f8a7b6c5d"
+
+	itemsURL := testWebURL + "/_api/web/lists(guid'" + libraryID + "')/items"
+	nextLink := itemsURL + "?%24skiptoken=Paged%3DTRUE%26p_ID%3D2&%24top=2"
+
+	tenant := newFakeTenant()
+	tenant.on(http.MethodGet, testWebURL+"/_api/web/lists/getbytitle('Contracts')", http.StatusOK, client.List{Id: libraryID, Title: "Contracts"})
+	tenant.on(http.MethodGet, itemsURL, http.StatusOK, map[string]any{
+		"value": []client.ListItem{
+			{Id: 1, FileSystemObjectType: client.FileSystemObjectTypeFolder, HasUniqueRoleAssignments: true},
+			{Id: 2, FileSystemObjectType: client.FileSystemObjectTypeFile, HasUniqueRoleAssignments: true},
+		},
+		"odata.nextLink": nextLink,
+	})
+	// the next link keeps the $top of the first page
+	tenant.on(http.MethodGet, nextLink, http.StatusOK, map[string]any{
+		"value": []client.ListItem{
+			{Id: 3, FileSystemObjectType: client.FileSystemObjectTypeFile, HasUniqueRoleAssignments: true},
+			{Id: 4, FileSystemObjectType: client.FileSystemObjectTypeFile, HasUniqueRoleAssignments: true},
+		},
+		"odata.nextLink": nextLink + "&p_ID=4",
+	})
+
+	library := LibraryScan{SiteURL: testWebURL, LibraryTitle: "Contracts"}
+	// the limit isn't a multiple of the page size
+	scanner := newItemScanner(newTestClient(t, tenant), []LibraryScan{library}, 3)
+
+	var (
+		scanned   []int
+		pageToken string
+	)
+	for pages := 0; pages == 0 || pageToken != ""; pages++ {
+		if pages > 2 {
+			t.Fatal("the scan doesn't end")
+		}
+
+		_, items, nextPageToken, err := scanner.scanPage(context.Background(), library, pageToken)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, item := range items {
+			scanned = append(scanned, item.Id)
+		}
+		pageToken = nextPageToken
+	}
+
+	if len(scanned) != 3 {
+		t.Errorf("got items %v, want the first 3", scanned)
+	}
+}
@@ -0,0 +1,185 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// listItemBuilder syncs the files or the folders with unique permissions
+// of the libraries configured to be scanned, they are children of their
+// site or web (subsite). The libraries are scanned once, by the folder
+// builder which lists the files along with the folders.
+type listItemBuilder struct {
+	client       *client.Client
+	scanner      *itemScanner
+	resourceType *v2.ResourceType
+}
+
+func (l *listItemBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return l.resourceType
+}
+
+func (l *listItemBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// files and folders are listed per site and per web, the files by the folder builder
+	if l.resourceType.Id != folderResourceType.Id || parentResourceID == nil || len(l.scanner.libraries) == 0 {
+		return nil, "", nil, nil
+	}
+	if parentResourceID.ResourceType != siteResourceType.Id && parentResourceID.ResourceType != webResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	_, webURL, err := webURLOf(ctx, l.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.List: %w", err)
+	}
+
+	bag := &pagination.Bag{}
+	err = bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// every page scans a page of one of the libraries of the site or web
+	if bag.Current() == nil {
+		// the libraries of subsites Microsoft Graph lists as sites are scanned under their web
+		if parentResourceID.ResourceType == siteResourceType.Id {
+			ok, err := isSiteCollection(ctx, l.client, webURL)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("listItemBuilder.List: %w", err)
+			}
+			if !ok {
+				return nil, "", nil, nil
+			}
+		}
+
+		for _, library := range l.scanner.librariesOf(webURL) {
+			bag.Push(pagination.PageState{ResourceTypeID: listResourceType.Id, ResourceID: library.LibraryTitle})
+		}
+		if bag.Current() == nil {
+			return nil, "", nil, nil
+		}
+	}
+
+	library := LibraryScan{SiteURL: strings.TrimSuffix(webURL, "/"), LibraryTitle: bag.ResourceID()}
+	listID, items, nextPageToken, err := l.scanner.scanPage(ctx, library, bag.PageToken())
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.List: %w", err)
+	}
+
+	var ret []*v2.Resource
+	for _, item := range items {
+		var resourceType *v2.ResourceType
+		switch item.FileSystemObjectType {
+		case client.FileSystemObjectTypeFolder:
+			resourceType = folderResourceType
+		case client.FileSystemObjectTypeFile:
+			resourceType = fileResourceType
+		default:
+			continue
+		}
+
+		rsc, err := convertListItem2Resource(resourceType, parentResourceID, library, listID, item)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("listItemBuilder.List: %w", err)
+		}
+		ret = append(ret, rsc)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	npt, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ret, npt, nil, nil
+}
+
+func (l *listItemBuilder) Entitlements(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	parentID, _, _, err := parseListItemResourceID(rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Entitlements: %w", err)
+	}
+
+	_, webURL, err := webURLOf(ctx, l.client, parentID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Entitlements: %w", err)
+	}
+
+	roleDefinitions, err := l.client.ListRoleDefinitions(ctx, webURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Entitlements: cannot list permission levels, error: %w", err)
+	}
+
+	return roleDefinitionEntitlements(rsc, roleDefinitions), "", nil, nil
+}
+
+func (l *listItemBuilder) Grants(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	parentID, listID, itemID, err := parseListItemResourceID(rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Grants: %w", err)
+	}
+
+	siteID, webURL, err := webURLOf(ctx, l.client, parentID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Grants: %w", err)
+	}
+
+	assignments, err := l.client.ListListItemRoleAssignments(ctx, webURL, listID, itemID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Grants: cannot list role assignments, error: %w", err)
+	}
+
+	ret, err := roleAssignmentGrants(ctx, siteID, rsc, assignments)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listItemBuilder.Grants: %w", err)
+	}
+
+	return mergeGrants(ret), "", nil, nil
+}
+
+func newFolderBuilder(c *client.Client, scanner *itemScanner) *listItemBuilder {
+	return &listItemBuilder{client: c, scanner: scanner, resourceType: folderResourceType}
+}
+
+func newFileBuilder(c *client.Client, scanner *itemScanner) *listItemBuilder {
+	return &listItemBuilder{client: c, scanner: scanner, resourceType: fileResourceType}
+}
+
+func convertListItem2Resource(resourceType *v2.ResourceType, parentResourceID *v2.ResourceId, library LibraryScan, listID string, item client.ListItem) (*v2.Resource, error) {
+	profile := map[string]any{
+		"name":     item.FileLeafRef,
+		"path":     item.FileRef,
+		"id":       item.Id,
+		"library":  library.LibraryTitle,
+		"list id":  listID,
+		"site url": library.SiteURL,
+	}
+
+	name := item.FileLeafRef
+	if name == "" {
+		name = path.Base(item.FileRef)
+	}
+
+	rsc, err := resource.NewGroupResource(name, resourceType, listItemResourceID(parentResourceID.Resource, listID, item.Id),
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(item.FileRef),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot make resource from '%s', error: %w", item.FileRef, err)
+	}
+
+	return rsc, nil
+}
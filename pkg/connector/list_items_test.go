@@ -0,0 +1,111 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+func TestListItemBuilderList(t *testing.T) {
+	const libraryID = "9a8b7c6d-5e4f-4a3b-2c1d-0e9f8a7b6c5d"
+
+	itemsURL := testWebURL + "/_api/web/lists(guid'" + libraryID + "')/items"
+	nextLink := itemsURL + "?%24skiptoken=Paged%3DTRUE%26p_ID%3D2&%24top=2"
+
+	tenant := newFakeTenant()
+	tenant.onSite(testSiteID, testSiteWebURL)
+	tenant.onWeb(testSiteWebURL, testWebID, testWebURL)
+	tenant.on(http.MethodGet, testWebURL+"/_api/web/lists/getbytitle('Contracts')", http.StatusOK, client.List{Id: libraryID, Title: "Contracts"})
+	tenant.on(http.MethodGet, itemsURL, http.StatusOK, map[string]any{
+		"value": []client.ListItem{
+			{Id: 1, FileSystemObjectType: client.FileSystemObjectTypeFolder, HasUniqueRoleAssignments: true, FileRef: "/sites/hr/benefits/Contracts/2024"},
+			{Id: 2, FileSystemObjectType: client.FileSystemObjectTypeFile, HasUniqueRoleAssignments: false, FileRef: "/sites/hr/benefits/Contracts/template.docx"},
+		},
+		"odata.nextLink": nextLink,
+	})
+	tenant.on(http.MethodGet, nextLink, http.StatusOK, map[string]any{
+		"value": []client.ListItem{
+			{Id: 3, FileSystemObjectType: client.FileSystemObjectTypeFolder, HasUniqueRoleAssignments: true, FileRef: "/sites/hr/benefits/Contracts/2025"},
+			{Id: 4, FileSystemObjectType: client.FileSystemObjectTypeFile, HasUniqueRoleAssignments: true, FileRef: "/sites/hr/benefits/Contracts/2025/offer.docx"},
+		},
+	})
+
+	libraries := []LibraryScan{{SiteURL: testWebURL, LibraryTitle: "Contracts"}}
+	web := &v2.ResourceId{ResourceType: webResourceType.Id, Resource: webResourceID(testSiteID, testWebID)}
+
+	var (
+		got   []string
+		token = &pagination.Token{}
+		pages = 0
+	)
+	for {
+		// every page uses a new builder, as if the sync was resumed
+		c := newTestClient(t, tenant)
+		builder := newFolderBuilder(c, newItemScanner(c, libraries, 0))
+
+		resources, npt, _, err := builder.List(context.Background(), web, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rsc := range resources {
+			if rsc.ParentResourceId.Resource != web.Resource {
+				t.Errorf("got parent '%s', want '%s'", rsc.ParentResourceId.Resource, web.Resource)
+			}
+			got = append(got, rsc.Id.ResourceType+":"+rsc.Id.Resource)
+		}
+
+		pages++
+		if npt == "" {
+			break
+		}
+		if pages > 2 {
+			t.Fatal("the scan doesn't end")
+		}
+		token = &pagination.Token{Token: npt}
+	}
+
+	// the files are listed along with the folders
+	want := []string{
+		folderResourceType.Id + ":" + listItemResourceID(web.Resource, libraryID, 1),
+		folderResourceType.Id + ":" + listItemResourceID(web.Resource, libraryID, 3),
+		fileResourceType.Id + ":" + listItemResourceID(web.Resource, libraryID, 4),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got items %v, want %v", got, want)
+	}
+	if pages != 2 {
+		t.Errorf("got %d pages, want 2", pages)
+	}
+
+	// the library isn't scanned a second time for the files
+	c := newTestClient(t, tenant)
+	resources, npt, _, err := newFileBuilder(c, newItemScanner(c, libraries, 0)).List(context.Background(), web, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 0 || npt != "" {
+		t.Errorf("got files %v and next page token %q, want none", resourceIDs(resources), npt)
+	}
+	// a request per page of the library
+	if n := tenant.requested(http.MethodGet, itemsURL); n != 2 {
+		t.Errorf("got %d requests for the items of the library, want 2", n)
+	}
+
+	// the site collection has no library to scan
+	builder := newFolderBuilder(c, newItemScanner(c, libraries, 0))
+	tenant.on(http.MethodGet, testSiteWebURL+"/_api/site", http.StatusOK, client.SharePointSite{Url: testSiteWebURL})
+
+	resources, npt, _, err = builder.List(context.Background(), &v2.ResourceId{ResourceType: siteResourceType.Id, Resource: testSiteID}, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 0 || npt != "" {
+		t.Errorf("got folders %v and next page token %q for the site collection, want none", resourceIDs(resources), npt)
+	}
+}
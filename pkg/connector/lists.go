@@ -0,0 +1,141 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// listBuilder syncs the lists and document libraries of every site and
+// web (subsite) that break permission inheritance, the ones that inherit
+// share the entitlements and grants of their site and are left out.
+type listBuilder struct {
+	client *client.Client
+	// also sync hidden (mostly system) lists
+	syncHiddenLists bool
+}
+
+func (l *listBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return listResourceType
+}
+
+func (l *listBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// lists are listed per site and per web
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+	if parentResourceID.ResourceType != siteResourceType.Id && parentResourceID.ResourceType != webResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	_, webURL, err := webURLOf(ctx, l.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.List: %w", err)
+	}
+
+	// the lists of subsites Microsoft Graph lists as sites are listed under their web
+	if parentResourceID.ResourceType == siteResourceType.Id {
+		ok, err := isSiteCollection(ctx, l.client, webURL)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("listBuilder.List: %w", err)
+		}
+		if !ok {
+			return nil, "", nil, nil
+		}
+	}
+
+	lists, err := l.client.ListLists(ctx, webURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.List: cannot list lists of site '%s', error: %w", webURL, err)
+	}
+
+	var ret []*v2.Resource
+	for _, list := range lists {
+		if !list.HasUniqueRoleAssignments {
+			continue
+		}
+		if list.Hidden && !l.syncHiddenLists {
+			continue
+		}
+
+		rsc, err := convertList2Resource(parentResourceID, webURL, list)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("listBuilder.List: %w", err)
+		}
+		ret = append(ret, rsc)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (l *listBuilder) Entitlements(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	parentID, _, err := parseListResourceID(rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Entitlements: %w", err)
+	}
+
+	_, webURL, err := webURLOf(ctx, l.client, parentID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Entitlements: %w", err)
+	}
+
+	roleDefinitions, err := l.client.ListRoleDefinitions(ctx, webURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Entitlements: cannot list permission levels, error: %w", err)
+	}
+
+	return roleDefinitionEntitlements(rsc, roleDefinitions), "", nil, nil
+}
+
+func (l *listBuilder) Grants(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	parentID, listID, err := parseListResourceID(rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Grants: %w", err)
+	}
+
+	siteID, webURL, err := webURLOf(ctx, l.client, parentID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Grants: %w", err)
+	}
+
+	assignments, err := l.client.ListListRoleAssignments(ctx, webURL, listID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Grants: cannot list role assignments, error: %w", err)
+	}
+
+	ret, err := roleAssignmentGrants(ctx, siteID, rsc, assignments)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("listBuilder.Grants: %w", err)
+	}
+
+	return mergeGrants(ret), "", nil, nil
+}
+
+func newListBuilder(c *client.Client, syncHiddenLists bool) *listBuilder {
+	return &listBuilder{client: c, syncHiddenLists: syncHiddenLists}
+}
+
+func convertList2Resource(parentResourceID *v2.ResourceId, webURL string, list client.List) (*v2.Resource, error) {
+	profile := map[string]any{
+		"title":               list.Title,
+		"id":                  list.Id,
+		"site url":            webURL,
+		"hidden":              list.Hidden,
+		"is document library": list.BaseType == client.ListBaseTypeDocumentLibrary,
+	}
+
+	rsc, err := resource.NewGroupResource(list.Title, listResourceType, listResourceID(parentResourceID.Resource, list.Id),
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot make resource from list '%s', error: %w", list.Title, err)
+	}
+
+	return rsc, nil
+}
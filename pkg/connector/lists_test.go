@@ -0,0 +1,83 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+const testWebURL = testSiteWebURL + "/benefits"
+
+// onWeb registers the web (subsite) webID of the site at siteWebURL.
+func (f *fakeTenant) onWeb(siteWebURL, webID, webURL string) {
+	f.on(http.MethodPost, siteWebURL+"/_api/site/openWebById", http.StatusOK, client.Web{Id: webID, Url: webURL})
+	f.on(http.MethodGet, webURL+"/_api/site", http.StatusOK, client.SharePointSite{Url: siteWebURL})
+}
+
+func TestListBuilderList(t *testing.T) {
+	const subsiteID = "contoso.sharepoint.com,8f7a9b5c-1234-4d5e-9f00-0a1b2c3d4e5f," + testWebID
+
+	tenant := newFakeTenant()
+	tenant.onSite(testSiteID, testSiteWebURL)
+	tenant.onWeb(testSiteWebURL, testWebID, testWebURL)
+	// Microsoft Graph lists the subsite as a site too
+	tenant.onSite(subsiteID, testWebURL)
+	tenant.on(http.MethodGet, testSiteWebURL+"/_api/site", http.StatusOK, client.SharePointSite{Url: testSiteWebURL})
+	tenant.on(http.MethodGet, testSiteWebURL+"/_api/web/lists", http.StatusOK, map[string]any{"value": []client.List{
+		{Id: "site-documents", Title: "Documents", HasUniqueRoleAssignments: true},
+		{Id: "site-inherited", Title: "Inherited", HasUniqueRoleAssignments: false},
+	}})
+	tenant.on(http.MethodGet, testWebURL+"/_api/web/lists", http.StatusOK, map[string]any{"value": []client.List{
+		{Id: "web-documents", Title: "Documents", HasUniqueRoleAssignments: true},
+		{Id: "web-hidden", Title: "Hidden", HasUniqueRoleAssignments: true, Hidden: true},
+	}})
+
+	webResource := &v2.ResourceId{ResourceType: webResourceType.Id, Resource: webResourceID(testSiteID, testWebID)}
+
+	testCases := []struct {
+		name   string
+		parent *v2.ResourceId
+		want   []string
+	}{
+		{
+			name:   "site",
+			parent: &v2.ResourceId{ResourceType: siteResourceType.Id, Resource: testSiteID},
+			want:   []string{listResourceID(testSiteID, "site-documents")},
+		},
+		{
+			name:   "web",
+			parent: webResource,
+			want:   []string{listResourceID(webResource.Resource, "web-documents")},
+		},
+		{
+			name:   "subsite listed as a site",
+			parent: &v2.ResourceId{ResourceType: siteResourceType.Id, Resource: subsiteID},
+			want:   []string{},
+		},
+	}
+
+	builder := newListBuilder(newTestClient(t, tenant), false)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, _, err := builder.List(context.Background(), tc.parent, &pagination.Token{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := resourceIDs(got); !slices.Equal(ids, tc.want) {
+				t.Errorf("got lists %v, want %v", ids, tc.want)
+			}
+			for _, rsc := range got {
+				if rsc.ParentResourceId.Resource != tc.parent.Resource {
+					t.Errorf("got parent '%s', want '%s'", rsc.ParentResourceId.Resource, tc.parent.Resource)
+				}
+			}
+		})
+	}
+}
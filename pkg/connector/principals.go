@@ -0,0 +1,112 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// loginNameForPrincipal makes the SharePoint login name (a claim) of an
+// Entra user or group, so it can be passed to `ensureuser` and friends.
+func loginNameForPrincipal(ctx context.Context, c *client.Client, principal *v2.Resource) (string, error) {
+	switch principal.Id.ResourceType {
+	case resourceTypeUser:
+		upn, err := userPrincipalNameOf(ctx, c, principal)
+		if err != nil {
+			return "", err
+		}
+		return claims.UserLoginName(upn), nil
+	case resourceTypeGroup:
+		return claims.EntraGroupLoginName(principal.Id.Resource), nil
+	case siteUserResourceType.Id, securityPrincipalResourceType.Id:
+		return principal.Id.Resource, nil
+	case appPrincipalResourceType.Id:
+		return appLoginNameOf(principal)
+	default:
+		return "", fmt.Errorf("principals of type '%s' are not supported", principal.Id.ResourceType)
+	}
+}
+
+// userPrincipalNameOf finds the user principal name of an Entra user, the
+// principal may not carry its user trait so Microsoft Graph is asked as last resort.
+func userPrincipalNameOf(ctx context.Context, c *client.Client, principal *v2.Resource) (string, error) {
+	if userTrait, err := resource.GetUserTrait(principal); err == nil {
+		if userTrait.Login != "" {
+			return userTrait.Login, nil
+		}
+		if upn, ok := resource.GetProfileStringValue(userTrait.Profile, "userPrincipalName"); ok && upn != "" {
+			return upn, nil
+		}
+	}
+
+	user, err := c.GetUserByID(ctx, principal.Id.Resource)
+	if err != nil {
+		return "", fmt.Errorf("cannot find the user principal name of user '%s', error: %w", principal.Id.Resource, err)
+	}
+	if user.UserPrincipalName == "" {
+		return "", fmt.Errorf("user '%s' has no user principal name", principal.Id.Resource)
+	}
+
+	return user.UserPrincipalName, nil
+}
+
+// appLoginNameOf makes the login name of an app principal, the tenant
+// the app belongs to is taken from its profile.
+func appLoginNameOf(principal *v2.Resource) (string, error) {
+	appTrait, err := resource.GetAppTrait(principal)
+	if err != nil {
+		return "", fmt.Errorf("app principal '%s' has no app trait, error: %w", principal.Id.Resource, err)
+	}
+
+	tenantID, ok := resource.GetProfileStringValue(appTrait.Profile, "tenant id")
+	if !ok || tenantID == "" {
+		return "", fmt.Errorf("app principal '%s' has no tenant ID", principal.Id.Resource)
+	}
+
+	return claims.AppLoginName(principal.Id.Resource, tenantID), nil
+}
+
+// isEntraUser tells if the security principal is a user of the Entra
+// tenant, guests invited by email are only known by SharePoint.
+func isEntraUser(securityPrincipal client.SecurityPrincipal, identity claims.Identity) bool {
+	return identity.Kind == claims.User &&
+		securityPrincipal.UserPrincipalName != "" &&
+		!securityPrincipal.IsEmailAuthenticationGuestUser &&
+		!securityPrincipal.IsShareByEmailGuestUser
+}
+
+// isSameSecurityPrincipal tells if the SharePoint security principal is the
+// one identified by loginName. Entra groups can show up with either their
+// "tenant" or "federateddirectoryclaimprovider" claim, both are the same group.
+func isSameSecurityPrincipal(securityPrincipal client.SecurityPrincipal, loginName string) bool {
+	if strings.EqualFold(securityPrincipal.LoginName, loginName) {
+		return true
+	}
+
+	lhs, err := claims.Parse(securityPrincipal.LoginName)
+	if err != nil {
+		return false
+	}
+	rhs, err := claims.Parse(loginName)
+	if err != nil {
+		return false
+	}
+
+	return claims.Same(lhs, rhs)
+}
+
+// findSecurityPrincipal looks for loginName among the security principals.
+func findSecurityPrincipal(securityPrincipals []client.SecurityPrincipal, loginName string) (client.SecurityPrincipal, bool) {
+	for _, securityPrincipal := range securityPrincipals {
+		if isSameSecurityPrincipal(securityPrincipal, loginName) {
+			return securityPrincipal, true
+		}
+	}
+
+	return client.SecurityPrincipal{}, false
+}
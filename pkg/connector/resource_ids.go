@@ -0,0 +1,282 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// Resource IDs are built out of the Microsoft Graph ID of the site
+// (`hostname,site collection GUID,web GUID`) and the numeric IDs
+// SharePoint uses inside the site, they don't change when the site is
+// renamed or its URL is changed.
+//
+// Before, resource IDs were URLs: the site's URL for sites and the
+// `odata.id` of groups. Those legacy IDs are still understood when
+// provisioning and are kept in the profile under "legacy resource id".
+
+// siteWebURLOf returns the URL of the site identified by siteID, which
+// may be a legacy resource ID.
+func siteWebURLOf(ctx context.Context, c *client.Client, siteID string) (string, error) {
+	if isLegacyResourceID(siteID) {
+		return siteID, nil
+	}
+
+	siteWebURL, err := c.GetSiteWebURL(ctx, siteID)
+	if err != nil {
+		return "", fmt.Errorf("cannot find the URL of site '%s', error: %w", siteID, err)
+	}
+
+	return siteWebURL, nil
+}
+
+// siteGraphIDOf returns the Microsoft Graph ID of the site identified by
+// siteID, which may be a legacy resource ID.
+func siteGraphIDOf(ctx context.Context, c *client.Client, siteID string) (string, error) {
+	if !isLegacyResourceID(siteID) {
+		return siteID, nil
+	}
+
+	site, err := c.GetSiteByWebURL(ctx, siteID)
+	if err != nil {
+		return "", fmt.Errorf("cannot find the ID of site '%s', error: %w", siteID, err)
+	}
+
+	return site.ID, nil
+}
+
+// webGraphIDOf returns the Microsoft Graph ID of a web (subsite) out of
+// the Graph ID of its site collection, `<hostname>,<site GUID>,<web GUID>`.
+func webGraphIDOf(siteGraphID, webID string) (string, error) {
+	parts := strings.Split(siteGraphID, ",")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed site ID '%s'", siteGraphID)
+	}
+
+	return strings.Join([]string{parts[0], parts[1], webID}, ","), nil
+}
+
+// webURLOf returns the URL of the site or web (subsite) identified by
+// parentID, the parent part of the resource IDs of lists and list items,
+// along with the ID of its site collection.
+func webURLOf(ctx context.Context, c *client.Client, parentID string) (string, string, error) {
+	if isLegacyResourceID(parentID) || !strings.Contains(parentID, "/") {
+		siteWebURL, err := siteWebURLOf(ctx, c, parentID)
+		if err != nil {
+			return "", "", err
+		}
+
+		return parentID, siteWebURL, nil
+	}
+
+	siteID, webID, err := parseWebResourceID(parentID)
+	if err != nil {
+		return "", "", err
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, c, siteID)
+	if err != nil {
+		return "", "", err
+	}
+
+	webURL, err := c.GetWebURL(ctx, siteWebURL, webID)
+	if err != nil {
+		return "", "", fmt.Errorf("cannot find the URL of subsite '%s', error: %w", webID, err)
+	}
+
+	return siteID, webURL, nil
+}
+
+// isSiteCollection tells if the site at siteWebURL is the root of its site
+// collection. Microsoft Graph lists some subsites as sites, their content
+// is synced under the webs of their site collection.
+func isSiteCollection(ctx context.Context, c *client.Client, siteWebURL string) (bool, error) {
+	siteCollection, err := c.GetSiteCollection(ctx, siteWebURL)
+	if err != nil {
+		return false, fmt.Errorf("cannot get site collection of '%s', error: %w", siteWebURL, err)
+	}
+
+	return strings.EqualFold(strings.TrimSuffix(siteCollection.Url, "/"), strings.TrimSuffix(siteWebURL, "/")), nil
+}
+
+func isLegacyResourceID(resourceID string) bool {
+	return strings.HasPrefix(resourceID, "https://")
+}
+
+// The parts of resource IDs are joined with "/", the "/" and "%" of the
+// parts are escaped so any part can be split back, even a legacy site ID.
+// Graph IDs, GUIDs and numeric IDs don't have either, they are kept as is.
+var (
+	resourceIDEscaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	resourceIDUnescaper = strings.NewReplacer("%25", "%", "%2F", "/")
+)
+
+// joinResourceID makes a resource ID out of its parts.
+func joinResourceID(parts ...string) string {
+	escaped := make([]string, 0, len(parts))
+	for _, part := range parts {
+		escaped = append(escaped, resourceIDEscaper.Replace(part))
+	}
+
+	return strings.Join(escaped, "/")
+}
+
+// splitResourceID splits a resource ID made by joinResourceID into its n
+// parts.
+func splitResourceID(resourceID string, n int) ([]string, bool) {
+	parts := strings.Split(resourceID, "/")
+	if len(parts) != n {
+		return nil, false
+	}
+
+	for i, part := range parts {
+		if part == "" {
+			return nil, false
+		}
+		parts[i] = resourceIDUnescaper.Replace(part)
+	}
+
+	return parts, true
+}
+
+// sharePointGroupResourceID makes the resource ID of a SharePoint group
+// out of the ID of its site and its numeric ID.
+func sharePointGroupResourceID(siteID string, groupID int) string {
+	return joinResourceID(siteID, strconv.Itoa(groupID))
+}
+
+// legacySharePointGroupResourceID makes the URL that was used as
+// resource ID of SharePoint groups, i.e. SharePoint's `odata.id`.
+func legacySharePointGroupResourceID(siteWebURL string, groupID int) string {
+	return fmt.Sprintf("%s/_api/Web/SiteGroups/GetById(%d)", strings.TrimSuffix(siteWebURL, "/"), groupID)
+}
+
+// parseSharePointGroupResourceID splits the resource ID of a SharePoint
+// group into the ID of its site and its numeric ID. For legacy resource
+// IDs the site ID is its URL.
+func parseSharePointGroupResourceID(resourceID string) (string, int, error) {
+	if isLegacyResourceID(resourceID) {
+		siteWebURL, groupPath, found := strings.Cut(resourceID, "/_api/")
+		if !found {
+			return "", 0, fmt.Errorf("malformed SharePoint group resource ID '%s'", resourceID)
+		}
+
+		var groupID int
+		_, err := fmt.Sscanf(groupPath, "Web/SiteGroups/GetById(%d)", &groupID)
+		if err != nil {
+			return "", 0, fmt.Errorf("malformed SharePoint group resource ID '%s', error: %w", resourceID, err)
+		}
+
+		return siteWebURL, groupID, nil
+	}
+
+	parts, ok := splitResourceID(resourceID, 2)
+	if !ok {
+		return "", 0, fmt.Errorf("malformed SharePoint group resource ID '%s'", resourceID)
+	}
+
+	groupID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed SharePoint group resource ID '%s', error: %w", resourceID, err)
+	}
+
+	return parts[0], groupID, nil
+}
+
+// roleDefinitionResourceID makes the resource ID of a permission level out
+// of the ID of its site and its numeric ID.
+func roleDefinitionResourceID(siteID string, roleDefinitionID int) string {
+	return joinResourceID(siteID, strconv.Itoa(roleDefinitionID))
+}
+
+// parseRoleDefinitionResourceID splits the resource ID of a permission
+// level into the ID of its site and its numeric ID.
+func parseRoleDefinitionResourceID(resourceID string) (string, int, error) {
+	parts, ok := splitResourceID(resourceID, 2)
+	if !ok {
+		return "", 0, fmt.Errorf("malformed permission level resource ID '%s'", resourceID)
+	}
+
+	roleDefinitionID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, fmt.Errorf("malformed permission level resource ID '%s', error: %w", resourceID, err)
+	}
+
+	return parts[0], roleDefinitionID, nil
+}
+
+// webResourceID makes the resource ID of a web (subsite) out of the ID of
+// its site collection and its GUID.
+func webResourceID(siteID, webID string) string {
+	return joinResourceID(siteID, webID)
+}
+
+// parseWebResourceID splits the resource ID of a web (subsite) into the ID
+// of its site collection and its GUID.
+func parseWebResourceID(resourceID string) (string, string, error) {
+	parts, ok := splitResourceID(resourceID, 2)
+	if !ok {
+		return "", "", fmt.Errorf("malformed web resource ID '%s'", resourceID)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// listResourceID makes the resource ID of a list or document library out
+// of the ID of its site or web (subsite) and its GUID.
+func listResourceID(parentID, listID string) string {
+	return joinResourceID(parentID, listID)
+}
+
+// parseListResourceID splits the resource ID of a list or document library
+// into the ID of its site or web (subsite) and its GUID.
+func parseListResourceID(resourceID string) (string, string, error) {
+	parts, ok := splitResourceID(resourceID, 2)
+	if !ok {
+		return "", "", fmt.Errorf("malformed list resource ID '%s'", resourceID)
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// listItemResourceID makes the resource ID of a file or folder out of the
+// ID of its site, the GUID of its library and its numeric ID.
+func listItemResourceID(siteID, listID string, itemID int) string {
+	return joinResourceID(siteID, listID, strconv.Itoa(itemID))
+}
+
+// parseListItemResourceID splits the resource ID of a file or folder into
+// the ID of its site, the GUID of its library and its numeric ID.
+func parseListItemResourceID(resourceID string) (string, string, int, error) {
+	parts, ok := splitResourceID(resourceID, 3)
+	if !ok {
+		return "", "", 0, fmt.Errorf("malformed list item resource ID '%s'", resourceID)
+	}
+
+	itemID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("malformed list item resource ID '%s', error: %w", resourceID, err)
+	}
+
+	return parts[0], parts[1], itemID, nil
+}
+
+// sharingLinkResourceID makes the resource ID of a sharing link out of the
+// ID of its site, the drive and item it shares and its permission ID.
+func sharingLinkResourceID(siteID, driveID, itemID, permissionID string) string {
+	return joinResourceID(siteID, driveID, itemID, permissionID)
+}
+
+// parseSharingLinkResourceID splits the resource ID of a sharing link into
+// the ID of its site, the drive and item it shares and its permission ID.
+func parseSharingLinkResourceID(resourceID string) (string, string, string, string, error) {
+	parts, ok := splitResourceID(resourceID, 4)
+	if !ok {
+		return "", "", "", "", fmt.Errorf("malformed sharing link resource ID '%s'", resourceID)
+	}
+
+	return parts[0], parts[1], parts[2], parts[3], nil
+}
@@ -0,0 +1,169 @@
+package connector
+
+import (
+	"testing"
+)
+
+const (
+	testSiteID       = "contoso.sharepoint.com,8f7a9b5c-1234-4d5e-9f00-0a1b2c3d4e5f,0e9d8c7b-5678-4a3b-8c00-f1e2d3c4b5a6"
+	testLegacySiteID = "https://contoso.sharepoint.com/sites/hr"
+	testWebID        = "5b0e4c2a-9d8e-4f1a-b2c3-d4e5f6a7b8c9"
+	testListID       = "3c1d2e4f-7a8b-4c9d-8e0f-1a2b3c4d5e6f"
+)
+
+func TestSharePointGroupResourceID(t *testing.T) {
+	testCases := []struct {
+		name       string
+		resourceID string
+		siteID     string
+		groupID    int
+	}{
+		{
+			name:       "site ID",
+			resourceID: sharePointGroupResourceID(testSiteID, 3),
+			siteID:     testSiteID,
+			groupID:    3,
+		},
+		{
+			name:       "legacy site ID",
+			resourceID: sharePointGroupResourceID(testLegacySiteID, 3),
+			siteID:     testLegacySiteID,
+			groupID:    3,
+		},
+		{
+			name:       "legacy group ID",
+			resourceID: legacySharePointGroupResourceID(testLegacySiteID+"/", 12),
+			siteID:     testLegacySiteID,
+			groupID:    12,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			siteID, groupID, err := parseSharePointGroupResourceID(tc.resourceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if siteID != tc.siteID || groupID != tc.groupID {
+				t.Errorf("got site '%s' and group %d, want site '%s' and group %d", siteID, groupID, tc.siteID, tc.groupID)
+			}
+		})
+	}
+
+	if got, want := sharePointGroupResourceID(testSiteID, 3), testSiteID+"/3"; got != want {
+		t.Errorf("got '%s', want '%s'", got, want)
+	}
+
+	for _, resourceID := range []string{
+		testSiteID,
+		testSiteID + "/owners",
+		testSiteID + "/3/4",
+		testLegacySiteID + "/_api/Web/Lists(1)",
+	} {
+		if _, _, err := parseSharePointGroupResourceID(resourceID); err == nil {
+			t.Errorf("expected an error for '%s'", resourceID)
+		}
+	}
+}
+
+func TestWebResourceID(t *testing.T) {
+	for _, siteID := range []string{testSiteID, testLegacySiteID} {
+		t.Run(siteID, func(t *testing.T) {
+			gotSiteID, gotWebID, err := parseWebResourceID(webResourceID(siteID, testWebID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotSiteID != siteID || gotWebID != testWebID {
+				t.Errorf("got site '%s' and web '%s', want site '%s' and web '%s'", gotSiteID, gotWebID, siteID, testWebID)
+			}
+		})
+	}
+
+	if _, _, err := parseWebResourceID(testSiteID); err == nil {
+		t.Error("expected an error for a site ID")
+	}
+}
+
+func TestListResourceID(t *testing.T) {
+	// lists belong to sites or webs
+	for _, parentID := range []string{testSiteID, testLegacySiteID, webResourceID(testSiteID, testWebID)} {
+		t.Run(parentID, func(t *testing.T) {
+			gotParentID, gotListID, err := parseListResourceID(listResourceID(parentID, testListID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotParentID != parentID || gotListID != testListID {
+				t.Errorf("got parent '%s' and list '%s', want parent '%s' and list '%s'", gotParentID, gotListID, parentID, testListID)
+			}
+		})
+	}
+
+	if _, _, err := parseListResourceID(testSiteID + "/" + testListID + "/1"); err == nil {
+		t.Error("expected an error for a list item ID")
+	}
+}
+
+func TestListItemResourceID(t *testing.T) {
+	for _, siteID := range []string{testSiteID, testLegacySiteID} {
+		t.Run(siteID, func(t *testing.T) {
+			gotSiteID, gotListID, gotItemID, err := parseListItemResourceID(listItemResourceID(siteID, testListID, 42))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotSiteID != siteID || gotListID != testListID || gotItemID != 42 {
+				t.Errorf("got site '%s', list '%s' and item %d, want site '%s', list '%s' and item 42", gotSiteID, gotListID, gotItemID, siteID, testListID)
+			}
+		})
+	}
+
+	for _, resourceID := range []string{
+		testSiteID + "/" + testListID,
+		testSiteID + "/" + testListID + "/first",
+	} {
+		if _, _, _, err := parseListItemResourceID(resourceID); err == nil {
+			t.Errorf("expected an error for '%s'", resourceID)
+		}
+	}
+}
+
+func TestSharingLinkResourceID(t *testing.T) {
+	const (
+		driveID = "b!kB4GEh5c0EaZ1lXp1hO4tLmBs8TStmJNjWm2JZ5bYqg"
+		itemID  = "01BYE5RZ6QN3ZWBTUFOFD3GSPGOHDJD36K"
+		// permission IDs are base64 and may have slashes
+		permissionID = "aTowIy5mfG1lbWJlcnNoaXB8/am9obkBjb250b3NvLmNvbQ=="
+	)
+
+	for _, siteID := range []string{testSiteID, testLegacySiteID} {
+		t.Run(siteID, func(t *testing.T) {
+			gotSiteID, gotDriveID, gotItemID, gotPermissionID, err := parseSharingLinkResourceID(sharingLinkResourceID(siteID, driveID, itemID, permissionID))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotSiteID != siteID || gotDriveID != driveID || gotItemID != itemID || gotPermissionID != permissionID {
+				t.Errorf("got '%s', '%s', '%s' and '%s', want '%s', '%s', '%s' and '%s'",
+					gotSiteID, gotDriveID, gotItemID, gotPermissionID, siteID, driveID, itemID, permissionID)
+			}
+		})
+	}
+
+	if _, _, _, _, err := parseSharingLinkResourceID(testSiteID + "/" + driveID + "/" + itemID); err == nil {
+		t.Error("expected an error for a resource ID without permission ID")
+	}
+}
+
+func TestRoleDefinitionResourceID(t *testing.T) {
+	testCases := []struct {
+		siteID string
+		want   string
+	}{
+		{siteID: testSiteID, want: testSiteID + "/1073741829"},
+		{siteID: testLegacySiteID, want: "https:%2F%2Fcontoso.sharepoint.com%2Fsites%2Fhr/1073741829"},
+	}
+
+	for _, tc := range testCases {
+		if got := roleDefinitionResourceID(tc.siteID, 1073741829); got != tc.want {
+			t.Errorf("got '%s', want '%s'", got, tc.want)
+		}
+	}
+}
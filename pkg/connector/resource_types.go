@@ -21,3 +21,72 @@ var securityPrincipalResourceType = &v2.ResourceType{
 	DisplayName: "Security Principal",
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
 }
+
+// entraUserResourceType and entraGroupResourceType are the types of the
+// users and groups synced by baton-microsoft-entra, this connector grants
+// entitlements to them without syncing them.
+var entraUserResourceType = &v2.ResourceType{
+	Id:          resourceTypeUser,
+	DisplayName: "User",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+var entraGroupResourceType = &v2.ResourceType{
+	Id:          resourceTypeGroup,
+	DisplayName: "Group",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var roleDefinitionResourceType = &v2.ResourceType{
+	Id:          "role_definition",
+	DisplayName: "Permission Level",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
+var tenantAudienceResourceType = &v2.ResourceType{
+	Id:          "tenant_audience",
+	DisplayName: "Tenant Audience",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var siteUserResourceType = &v2.ResourceType{
+	Id:          "site_user",
+	DisplayName: "SharePoint User",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_USER},
+}
+
+var appPrincipalResourceType = &v2.ResourceType{
+	Id:          "app_principal",
+	DisplayName: "App Principal",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_APP},
+}
+
+var webResourceType = &v2.ResourceType{
+	Id:          "web",
+	DisplayName: "Subsite",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var listResourceType = &v2.ResourceType{
+	Id:          "list",
+	DisplayName: "List",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var folderResourceType = &v2.ResourceType{
+	Id:          "folder",
+	DisplayName: "Folder",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var fileResourceType = &v2.ResourceType{
+	Id:          "file",
+	DisplayName: "File",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var sharingLinkResourceType = &v2.ResourceType{
+	Id:          "sharing_link",
+	DisplayName: "Sharing Link",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
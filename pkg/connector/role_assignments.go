@@ -0,0 +1,241 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// roleDefinitionEntitlementSlug makes the slug of the entitlement that
+// represents a permission level. The ID of the role definition is used
+// because the name of the permission level can be renamed or localized.
+func roleDefinitionEntitlementSlug(roleDefinitionID int) string {
+	return fmt.Sprintf("role:%d", roleDefinitionID)
+}
+
+// roleDefinitionIDOf returns the ID of the role definition of a permission
+// level entitlement slug, ok is false for any other slug.
+func roleDefinitionIDOf(slug string) (int, bool) {
+	if !strings.HasPrefix(slug, "role:") {
+		return 0, false
+	}
+
+	var roleDefinitionID int
+	if _, err := fmt.Sscanf(slug, "role:%d", &roleDefinitionID); err != nil {
+		return 0, false
+	}
+
+	return roleDefinitionID, true
+}
+
+// roleDefinitionEntitlements makes an entitlement for every permission
+// level that can be assigned on a securable object (like a site).
+func roleDefinitionEntitlements(rsc *v2.Resource, roleDefinitions []client.RoleDefinition) []*v2.Entitlement {
+	var ret []*v2.Entitlement
+	for _, roleDefinition := range roleDefinitions {
+		if roleDefinition.Hidden { // i.e. "Limited Access", SharePoint hands it out on its own
+			continue
+		}
+
+		ret = append(ret, entitlement.NewPermissionEntitlement(rsc, roleDefinitionEntitlementSlug(roleDefinition.Id),
+			entitlement.WithDisplayName(fmt.Sprintf("%s on %s", roleDefinition.Name, rsc.DisplayName)),
+			entitlement.WithDescription(roleDefinition.Description),
+		))
+	}
+
+	return ret
+}
+
+// roleAssignmentGrants converts the role assignments of a securable
+// object (like a site) into grants of its permission level entitlements.
+// siteID is the site collection the SharePoint groups belong to.
+func roleAssignmentGrants(ctx context.Context, siteID string, rsc *v2.Resource, assignments []client.RoleAssignment) ([]*v2.Grant, error) {
+	var ret []*v2.Grant
+
+	for _, assignment := range assignments {
+		for _, roleDefinition := range assignment.RoleDefinitionBindings {
+			if roleDefinition.Hidden { // no entitlement is made for hidden permission levels
+				continue
+			}
+
+			slug := roleDefinitionEntitlementSlug(roleDefinition.Id)
+
+			if assignment.Member.PrincipalType == client.SharePointGroup {
+				ret = append(ret, sharePointGroupGrant(siteID, rsc, slug, assignment.Member))
+				continue
+			}
+
+			granted, isGrantable, err := grantHelper(ctx, assignment.Member, slug, rsc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to grant permission level '%s', error: %w", roleDefinition.Name, err)
+			}
+			if !isGrantable {
+				continue
+			}
+			ret = append(ret, granted)
+		}
+	}
+
+	return ret, nil
+}
+
+// sharePointGroupGrant grants the entitlement to a SharePoint group of the
+// site, the grant is expanded to the members of the group.
+func sharePointGroupGrant(siteID string, rsc *v2.Resource, slug string, group client.SecurityPrincipal) *v2.Grant {
+	principal := &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: groupResourceType.Id,
+			Resource:     sharePointGroupResourceID(siteID, group.Id),
+		},
+	}
+
+	return grant.NewGrant(rsc, slug, principal, grant.WithAnnotation(&v2.GrantExpandable{
+		EntitlementIds: []string{entitlement.NewEntitlementID(principal, groupMemberEntitlement)},
+		Shallow:        true,
+	}))
+}
+
+// grantRoleDefinition gives the principal a permission level on the site.
+// Users and apps are added to the site first, SharePoint groups must
+// belong to the site.
+func grantRoleDefinition(ctx context.Context, c *client.Client, principal *v2.Resource, ent *v2.Entitlement, roleDefinitionID int) ([]*v2.Grant, annotations.Annotations, error) {
+	siteWebURL, err := siteWebURLOf(ctx, c, ent.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	assignments, err := c.ListRoleAssignments(ctx, siteWebURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot list role assignments, error: %w", err)
+	}
+
+	var principalID int
+	if principal.Id.ResourceType == groupResourceType.Id {
+		principalID, err = siteGroupIDOf(ctx, c, siteWebURL, principal)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		loginName, err := loginNameForPrincipal(ctx, c, principal)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if assignment, found := findRoleAssignment(assignments, loginName); found && hasRoleDefinition(assignment, roleDefinitionID) {
+			return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+
+		user, err := c.EnsureUser(ctx, siteWebURL, loginName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot add '%s' to site, error: %w", loginName, err)
+		}
+		principalID = user.Id
+	}
+
+	if assignment, found := findRoleAssignmentByPrincipalID(assignments, principalID); found && hasRoleDefinition(assignment, roleDefinitionID) {
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	err = c.AddRoleAssignment(ctx, siteWebURL, principalID, roleDefinitionID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot give permission level %d to '%s', error: %w", roleDefinitionID, principal.Id.Resource, err)
+	}
+
+	return []*v2.Grant{grant.NewGrant(ent.Resource, ent.Slug, principal.Id)}, nil, nil
+}
+
+// revokeRoleDefinition takes a permission level on the site away from the
+// principal of the grant.
+func revokeRoleDefinition(ctx context.Context, c *client.Client, toRevoke *v2.Grant, roleDefinitionID int) (annotations.Annotations, error) {
+	siteWebURL, err := siteWebURLOf(ctx, c, toRevoke.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments, err := c.ListRoleAssignments(ctx, siteWebURL)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list role assignments, error: %w", err)
+	}
+
+	var (
+		assignment client.RoleAssignment
+		found      bool
+	)
+	if toRevoke.Principal.Id.ResourceType == groupResourceType.Id {
+		groupID, err := siteGroupIDOf(ctx, c, siteWebURL, toRevoke.Principal)
+		if err != nil {
+			return nil, err
+		}
+		assignment, found = findRoleAssignmentByPrincipalID(assignments, groupID)
+	} else {
+		loginName, err := loginNameForPrincipal(ctx, c, toRevoke.Principal)
+		if err != nil {
+			return nil, err
+		}
+		assignment, found = findRoleAssignment(assignments, loginName)
+	}
+	if !found || !hasRoleDefinition(assignment, roleDefinitionID) {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	err = c.RemoveRoleAssignment(ctx, siteWebURL, assignment.PrincipalId, roleDefinitionID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot take permission level %d away from '%s', error: %w", roleDefinitionID, toRevoke.Principal.Id.Resource, err)
+	}
+
+	return nil, nil
+}
+
+// siteGroupIDOf returns the numeric ID of a SharePoint group, which must
+// belong to the site at siteWebURL.
+func siteGroupIDOf(ctx context.Context, c *client.Client, siteWebURL string, principal *v2.Resource) (int, error) {
+	siteID, groupID, err := parseSharePointGroupResourceID(principal.Id.Resource)
+	if err != nil {
+		return 0, err
+	}
+
+	groupSiteWebURL, err := siteWebURLOf(ctx, c, siteID)
+	if err != nil {
+		return 0, err
+	}
+	if !strings.EqualFold(strings.TrimSuffix(groupSiteWebURL, "/"), strings.TrimSuffix(siteWebURL, "/")) {
+		return 0, fmt.Errorf("SharePoint group '%s' doesn't belong to site '%s'", principal.Id.Resource, siteWebURL)
+	}
+
+	return groupID, nil
+}
+
+// findRoleAssignment finds the role assignment of the user or group with the given login name.
+func findRoleAssignment(assignments []client.RoleAssignment, loginName string) (client.RoleAssignment, bool) {
+	for _, assignment := range assignments {
+		if assignment.Member.PrincipalType != client.SharePointGroup && isSameSecurityPrincipal(assignment.Member, loginName) {
+			return assignment, true
+		}
+	}
+
+	return client.RoleAssignment{}, false
+}
+
+// findRoleAssignmentByPrincipalID finds the role assignment of the user or group with the given ID.
+func findRoleAssignmentByPrincipalID(assignments []client.RoleAssignment, principalID int) (client.RoleAssignment, bool) {
+	for _, assignment := range assignments {
+		if assignment.PrincipalId == principalID {
+			return assignment, true
+		}
+	}
+
+	return client.RoleAssignment{}, false
+}
+
+func hasRoleDefinition(assignment client.RoleAssignment, roleDefinitionID int) bool {
+	return slices.ContainsFunc(assignment.RoleDefinitionBindings, func(roleDefinition client.RoleDefinition) bool {
+		return roleDefinition.Id == roleDefinitionID
+	})
+}
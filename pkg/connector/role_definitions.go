@@ -0,0 +1,97 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+type roleDefinitionBuilder struct {
+	client *client.Client
+}
+
+func (r *roleDefinitionBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return roleDefinitionResourceType
+}
+
+func (r *roleDefinitionBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// permission levels are listed per site
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, r.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	roleDefinitions, err := r.client.ListRoleDefinitions(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("unable to list SharePoint permission levels of site '%s', error: %w", siteWebURL, err)
+	}
+
+	var ret []*v2.Resource
+	for _, roleDefinition := range roleDefinitions {
+		rsc, err := convertRoleDefinition2Resource(parentResourceID, siteWebURL, roleDefinition)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, rsc)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (r *roleDefinitionBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (r *roleDefinitionBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newRoleDefinitionBuilder(c *client.Client) *roleDefinitionBuilder {
+	return &roleDefinitionBuilder{client: c}
+}
+
+func convertRoleDefinition2Resource(siteID *v2.ResourceId, siteWebURL string, roleDefinition client.RoleDefinition) (*v2.Resource, error) {
+	kinds, err := roleDefinition.BasePermissions.Rights()
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode rights of permission level '%s', error: %w", roleDefinition.Name, err)
+	}
+
+	rights := make([]any, 0, len(kinds))
+	for _, kind := range kinds {
+		rights = append(rights, kind.String())
+	}
+
+	profile := map[string]any{
+		"name":             roleDefinition.Name,
+		"description":      roleDefinition.Description,
+		"id":               roleDefinition.Id,
+		"hidden":           roleDefinition.Hidden,
+		"built-in":         roleDefinition.RoleTypeKind != 0,
+		"site url":         siteWebURL,
+		"rights":           rights,
+		"base permissions": fmt.Sprintf("High: %s, Low: %s", roleDefinition.BasePermissions.High, roleDefinition.BasePermissions.Low),
+	}
+
+	rsc, err := resource.NewRoleResource(
+		roleDefinition.Name,
+		roleDefinitionResourceType,
+		roleDefinitionResourceID(siteID.Resource, roleDefinition.Id),
+		[]resource.RoleTraitOption{resource.WithRoleProfile(profile)},
+		resource.WithParentResourceID(siteID),
+		resource.WithDescription(roleDefinition.Description),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resource from SharePoint permission level, err: %w", err)
+	}
+
+	return rsc, nil
+}
@@ -3,15 +3,20 @@ package connector
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
 	"github.com/conductorone/baton-sharepoint/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
+// securityPrincipalBuilder syncs the on-premises security groups SharePoint
+// knows about. They are listed per site; a group with access to many sites
+// is listed on each of them under the same ID and stored once.
 type securityPrincipalBuilder struct {
 	client *client.Client
 }
@@ -21,53 +26,48 @@ func (s *securityPrincipalBuilder) ResourceType(ctx context.Context) *v2.Resourc
 }
 
 func (s *securityPrincipalBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
-	bag := &pagination.Bag{}
-	err := bag.Unmarshal(pToken.Token)
+	// security principals are listed per site
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, s.client, parentResourceID.Resource)
 	if err != nil {
 		return nil, "", nil, err
 	}
-	if bag.Current() != nil {
-		bag.Push(pagination.PageState{ResourceTypeID: groupResourceType.Id})
-	}
 
-	sites, err := s.client.ListSites(ctx, bag)
+	users, err := s.client.ListSecurityPrincipals(ctx, siteWebURL)
 	if err != nil {
-		return nil, "", nil, fmt.Errorf("unable to list SharePoint security principals, error: %w", err)
+		return nil, "", nil, fmt.Errorf("unable to list SharePoint security principals of site '%s', error: %w", siteWebURL, err)
 	}
 
 	var ret []*v2.Resource
-
-	for _, site := range sites {
-		users, err := s.client.ListSecurityPrincipals(ctx, site.WebUrl)
+	for _, user := range users {
+		identity, err := claims.Parse(user.LoginName)
 		if err != nil {
-			return nil, "", nil, err
+			ctxzap.Extract(ctx).Warn("cannot identify SharePoint security principal, skipping", zap.String("site", siteWebURL), zap.String("principal", user.LoginName), zap.Error(err))
+			continue
 		}
 
-		for _, user := range users {
-			// ignore Entra users, Microsoft 365 Groups, Entra groups and "system" users
-			if user.PrincipalType == client.SecurityGroup &&
-				!strings.Contains(user.LoginName, "federateddirectoryclaimprovider") &&
-				!strings.Contains(user.LoginName, "|tenant|") {
-				spResource, err := resource.NewGroupResource(
-					user.Title,
-					securityPrincipalResourceType,
-					user.LoginName,
-					nil,
-				)
-				if err != nil {
-					return nil, "", nil, fmt.Errorf("cannot create resource from SharePoint security principal, err: %w", err)
-				}
-				ret = append(ret, spResource)
+		// ignore Entra users, Microsoft 365 Groups, Entra groups, tenant audiences and "system" users
+		if _, ok := tenantAudienceOf(identity); ok {
+			continue
+		}
+		if user.PrincipalType == client.SecurityGroup && !identity.IsEntraGroup() {
+			spResource, err := resource.NewGroupResource(
+				user.Title,
+				securityPrincipalResourceType,
+				user.LoginName,
+				nil,
+			)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("cannot create resource from SharePoint security principal, err: %w", err)
 			}
+			ret = append(ret, spResource)
 		}
 	}
 
-	ntp, err := bag.Marshal()
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	return ret, ntp, nil, nil
+	return ret, "", nil, nil
 }
 
 func (s *securityPrincipalBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
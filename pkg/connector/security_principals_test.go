@@ -0,0 +1,32 @@
+package connector
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+func TestSecurityPrincipalBuilderList(t *testing.T) {
+	securityGroup := client.SecurityPrincipal{
+		Id:            12,
+		Title:         `CONTOSO\Domain Users`,
+		LoginName:     `c:0+.w|s-1-5-21-2127521184-1604012920-1887927527-513`,
+		PrincipalType: client.SecurityGroup,
+	}
+
+	tenant := newFakeTenant()
+	tenant.onSite(testSiteID, testSiteWebURL)
+	tenant.onSite(testFinanceSiteID, testFinanceSiteWebURL)
+	tenant.onSecurityPrincipals(testSiteWebURL, testEntraUser, securityGroup)
+	tenant.onSecurityPrincipals(testFinanceSiteWebURL, securityGroup)
+
+	builder := newSecurityPrincipalBuilder(newTestClient(t, tenant))
+
+	// the group is keyed by login name, the one grants are made to, on both sites
+	got := listTenantWide(t, builder, testSiteID, testFinanceSiteID)
+	want := [][]string{{securityGroup.LoginName}, {securityGroup.LoginName}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got security principals %v, want %v", got, want)
+	}
+}
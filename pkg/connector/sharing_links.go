@@ -0,0 +1,576 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// sharingLinkRecipientEntitlement is the slug of the entitlement granted to
+// the people a sharing link was shared with.
+const sharingLinkRecipientEntitlement = "recipient"
+
+// sharingLinkScopeAnonymous is the scope of "Anyone" links, they don't require signing in.
+const sharingLinkScopeAnonymous = "anonymous"
+
+// sharingLinkItemsPerPage is the number of shared files and folders whose
+// links are listed per page, every item takes a few Microsoft Graph calls.
+const sharingLinkItemsPerPage = 25
+
+// sharingLinkRecipientsPerPage is the number of recipients of a sharing link granted per page.
+const sharingLinkRecipientsPerPage = 100
+
+// SharePoint makes a limited access group for every sharing link, named
+// `SharingLinks.<unique ID of the file or folder>.<kind of link>.<share ID>`.
+var sharingLinksGroupTitle = regexp.MustCompile(`^SharingLinks\.([0-9a-fA-F-]{36})\.([A-Za-z]+)\.([0-9a-fA-F-]{36})$`)
+
+// sharedItemOfGroup returns the unique ID of the file or folder shared by a
+// `SharingLinks` group, if the group is one.
+func sharedItemOfGroup(group client.SharePointSiteGroup) (string, bool) {
+	matches := sharingLinksGroupTitle.FindStringSubmatch(group.Title)
+	if matches == nil {
+		return "", false
+	}
+
+	return strings.ToLower(matches[1]), true
+}
+
+// sharedItemsOf returns the unique IDs of the files and folders shared by
+// the `SharingLinks` groups, sorted. Every link of an item has its own group.
+func sharedItemsOf(groups []client.SharePointSiteGroup) []string {
+	var ret []string
+	for _, group := range groups {
+		if uniqueID, ok := sharedItemOfGroup(group); ok {
+			ret = append(ret, uniqueID)
+		}
+	}
+	slices.Sort(ret)
+
+	return slices.Compact(ret)
+}
+
+// offsetPage returns the bounds of the page of pageSize elements out of n
+// starting at the offset in pageToken, and the token of the next page.
+func offsetPage(pageToken string, n, pageSize int) (int, int, string, error) {
+	start := 0
+	if pageToken != "" {
+		offset, err := strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return 0, 0, "", fmt.Errorf("invalid page token '%s'", pageToken)
+		}
+		start = min(offset, n)
+	}
+
+	end := min(start+pageSize, n)
+	if end == n {
+		return start, end, "", nil
+	}
+
+	return start, end, strconv.Itoa(end), nil
+}
+
+var sharingLinkScopeLabels = map[string]string{
+	sharingLinkScopeAnonymous: "Anyone",
+	"organization":            "Organization",
+	"users":                   "Specific people",
+	"existingAccess":          "People with existing access",
+}
+
+// sharedItemFinder finds the files and folders shared by the sharing links
+// of a site collection, they may be in its root web or in any of its webs
+// (subsites). The webs are listed the first time an item isn't in the root web.
+type sharedItemFinder struct {
+	client     *client.Client
+	siteWebURL string
+	webs       []client.Web
+	websListed bool
+}
+
+// find returns the list item of the file or folder, along with the ID of
+// the web it's in, empty for the root web.
+func (f *sharedItemFinder) find(ctx context.Context, uniqueID string) (*client.SharedListItem, string, error) {
+	listItem, err := f.client.GetListItemByUniqueID(ctx, f.siteWebURL, uniqueID)
+	if err == nil {
+		return listItem, "", nil
+	}
+
+	if !f.websListed {
+		f.webs, err = listAllWebs(ctx, f.client, f.siteWebURL)
+		if err != nil {
+			return nil, "", err
+		}
+		f.websListed = true
+	}
+
+	for _, web := range f.webs {
+		listItem, webErr := f.client.GetListItemByUniqueID(ctx, web.Url, uniqueID)
+		if webErr == nil {
+			return listItem, web.Id, nil
+		}
+	}
+
+	return nil, "", err
+}
+
+// listAllWebs lists the webs (subsites) of the site collection at
+// siteWebURL, no matter how deep they are.
+func listAllWebs(ctx context.Context, c *client.Client, siteWebURL string) ([]client.Web, error) {
+	var ret []client.Web
+	for parents := []string{siteWebURL}; len(parents) > 0; parents = parents[1:] {
+		webs, err := c.ListWebs(ctx, parents[0])
+		if err != nil {
+			return nil, fmt.Errorf("cannot list subsites of '%s', error: %w", parents[0], err)
+		}
+
+		for _, web := range webs {
+			ret = append(ret, web)
+			parents = append(parents, web.Url)
+		}
+	}
+
+	return ret, nil
+}
+
+// sharingLinkBuilder syncs the sharing links of the files and folders of
+// every site collection, subsites included, as children of the site
+// collection. Links are found through the `SharingLinks` groups SharePoint
+// makes for them, their details come from the permissions of the drive
+// item on Microsoft Graph.
+type sharingLinkBuilder struct {
+	client *client.Client
+}
+
+func (s *sharingLinkBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return sharingLinkResourceType
+}
+
+func (s *sharingLinkBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// sharing links are listed per site
+	if parentResourceID == nil || parentResourceID.ResourceType != siteResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	l := ctxzap.Extract(ctx)
+
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, s.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+	}
+
+	if bag.Current() == nil {
+		// the groups are the ones of the site collection, the links of
+		// the subsites Microsoft Graph lists as sites are listed with it
+		ok, err := isSiteCollection(ctx, s.client, siteWebURL)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+		}
+		if !ok {
+			return nil, "", nil, nil
+		}
+
+		bag.Push(pagination.PageState{ResourceTypeID: sharingLinkResourceType.Id})
+	}
+
+	siteGraphID, err := siteGraphIDOf(ctx, s.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+	}
+
+	groups, err := s.client.ListGroupsForSite(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: cannot list groups of site '%s', error: %w", siteWebURL, err)
+	}
+
+	// every page lists the links of a page of the shared items
+	uniqueIDs := sharedItemsOf(groups)
+	start, end, nextPageToken, err := offsetPage(bag.PageToken(), len(uniqueIDs), sharingLinkItemsPerPage)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+	}
+
+	finder := &sharedItemFinder{client: s.client, siteWebURL: siteWebURL}
+
+	var ret []*v2.Resource
+	for _, uniqueID := range uniqueIDs[start:end] {
+		// groups may outlive the file or folder they were made for
+		listItem, webID, err := finder.find(ctx, uniqueID)
+		if err != nil {
+			l.Warn("cannot find the item shared by a sharing link, skipping", zap.String("site", siteWebURL), zap.String("item", uniqueID), zap.Error(err))
+			continue
+		}
+
+		graphID := siteGraphID
+		if webID != "" {
+			graphID, err = webGraphIDOf(siteGraphID, webID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+			}
+		}
+
+		driveItem, err := s.client.GetDriveItemOfListItem(ctx, graphID, listItem.ParentList.Id, listItem.Id)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: cannot get the drive item '%s', error: %w", uniqueID, err)
+		}
+
+		permissions, err := s.client.ListDriveItemPermissions(ctx, driveItem.ParentReference.DriveID, driveItem.ID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: cannot list permissions of '%s', error: %w", driveItem.WebUrl, err)
+		}
+
+		for _, permission := range permissions {
+			if permission.Link == nil { // not a sharing link
+				continue
+			}
+
+			rsc, err := convertSharingLink2Resource(parentResourceID, siteWebURL, driveItem, permission)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("sharingLinkBuilder.List: %w", err)
+			}
+			ret = append(ret, rsc)
+		}
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	npt, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ret, npt, nil, nil
+}
+
+func (s *sharingLinkBuilder) Entitlements(_ context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	ent := entitlement.NewAssignmentEntitlement(rsc, sharingLinkRecipientEntitlement,
+		entitlement.WithDisplayName(fmt.Sprintf("Recipient of %s", rsc.DisplayName)),
+		entitlement.WithDescription("People the sharing link was shared with"),
+	)
+
+	return []*v2.Entitlement{ent}, "", nil, nil
+}
+
+func (s *sharingLinkBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	_, driveID, itemID, permissionID, err := parseSharingLinkResourceID(rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.Grants: %w", err)
+	}
+
+	bag := &pagination.Bag{}
+	err = bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if bag.Current() == nil {
+		bag.Push(pagination.PageState{ResourceTypeID: sharingLinkResourceType.Id})
+	}
+
+	permission, err := s.client.GetDriveItemPermission(ctx, driveID, itemID, permissionID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.Grants: cannot get sharing link, error: %w", err)
+	}
+
+	recipients := permission.GrantedToIdentitiesV2
+	start, end, nextPageToken, err := offsetPage(bag.PageToken(), len(recipients), sharingLinkRecipientsPerPage)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("sharingLinkBuilder.Grants: %w", err)
+	}
+
+	var ret []*v2.Grant
+	for _, recipient := range recipients[start:end] {
+		granted, isGrantable, err := s.recipientGrant(ctx, rsc, recipient)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("sharingLinkBuilder.Grants: %w", err)
+		}
+		if !isGrantable {
+			continue
+		}
+		ret = append(ret, granted)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	npt, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return mergeGrants(ret), npt, nil, nil
+}
+
+func (s *sharingLinkBuilder) Grant(_ context.Context, _ *v2.Resource, ent *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	return nil, nil, fmt.Errorf("sharingLinkBuilder.Grant: entitlement '%s' cannot be granted, share the item again instead", ent.Id)
+}
+
+// Revoke removes a recipient from a sharing link, the link keeps working
+// for the rest of its recipients.
+func (s *sharingLinkBuilder) Revoke(ctx context.Context, toRevoke *v2.Grant) (annotations.Annotations, error) {
+	if toRevoke.Entitlement.Slug != sharingLinkRecipientEntitlement {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: entitlement '%s' cannot be revoked", toRevoke.Entitlement.Id)
+	}
+
+	_, driveID, itemID, permissionID, err := parseSharingLinkResourceID(toRevoke.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: %w", err)
+	}
+
+	permission, err := s.client.GetDriveItemPermission(ctx, driveID, itemID, permissionID)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: cannot get sharing link, error: %w", err)
+	}
+
+	recipient, found, err := s.findRecipient(ctx, permission.GrantedToIdentitiesV2, toRevoke.Principal)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: %w", err)
+	}
+	if !found {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	grantee, err := driveRecipientOf(toRevoke.Principal, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: %w", err)
+	}
+
+	err = s.client.RevokeDrivePermissionGrants(ctx, driveID, itemID, permissionID, []client.DriveRecipient{grantee})
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Revoke: cannot revoke '%s' from sharing link, error: %w", toRevoke.Principal.Id.Resource, err)
+	}
+
+	return nil, nil
+}
+
+// Delete deletes the sharing link, i.e. an organization or anyone link,
+// so it stops working for everyone.
+func (s *sharingLinkBuilder) Delete(ctx context.Context, resourceId *v2.ResourceId) (annotations.Annotations, error) {
+	if resourceId.ResourceType != sharingLinkResourceType.Id {
+		return nil, fmt.Errorf("sharingLinkBuilder.Delete: resources of type '%s' cannot be deleted", resourceId.ResourceType)
+	}
+
+	_, driveID, itemID, permissionID, err := parseSharingLinkResourceID(resourceId.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Delete: %w", err)
+	}
+
+	err = s.client.DeleteDrivePermission(ctx, driveID, itemID, permissionID)
+	if err != nil {
+		return nil, fmt.Errorf("sharingLinkBuilder.Delete: cannot delete sharing link, error: %w", err)
+	}
+
+	return nil, nil
+}
+
+func newSharingLinkBuilder(c *client.Client) *sharingLinkBuilder {
+	return &sharingLinkBuilder{client: c}
+}
+
+// recipientGrant grants the recipient entitlement of the sharing link to
+// a recipient, the same way grantHelper does for SharePoint: Entra users
+// are always granted by user principal name, whether Microsoft Graph
+// reports them as a SharePoint user or as an Entra user.
+func (s *sharingLinkBuilder) recipientGrant(ctx context.Context, rsc *v2.Resource, recipient client.IdentitySet) (*v2.Grant, bool, error) {
+	switch {
+	case recipient.SiteUser != nil && recipient.SiteUser.LoginName != "":
+		securityPrincipal := client.SecurityPrincipal{
+			Title:         recipient.SiteUser.DisplayName,
+			Email:         recipient.SiteUser.Email,
+			LoginName:     recipient.SiteUser.LoginName,
+			PrincipalType: client.User,
+		}
+
+		// the user principal name of Entra users is part of their login name
+		parsed, err := claims.Parse(recipient.SiteUser.LoginName)
+		if err != nil {
+			// an odd login name must not stop the rest of the recipients from syncing
+			ctxzap.Extract(ctx).Warn("cannot identify recipient of sharing link, skipping", zap.String("sharing link", rsc.Id.Resource), zap.String("recipient", recipient.SiteUser.LoginName), zap.Error(err))
+			return nil, false, nil
+		}
+		if parsed.Kind == claims.User {
+			securityPrincipal.UserPrincipalName = parsed.Identifier
+		}
+
+		return grantHelper(ctx, securityPrincipal, sharingLinkRecipientEntitlement, rsc)
+	case recipient.User != nil && recipient.User.ID != "":
+		// only the object ID is known, the user principal name comes from Entra
+		user, err := s.client.GetUserByID(ctx, recipient.User.ID)
+		if err != nil {
+			return nil, false, fmt.Errorf("cannot find recipient '%s', error: %w", recipient.User.ID, err)
+		}
+		if user.UserPrincipalName == "" {
+			return nil, false, fmt.Errorf("recipient '%s' has no user principal name", recipient.User.ID)
+		}
+
+		securityPrincipal := client.SecurityPrincipal{
+			Title:             user.DisplayName,
+			Email:             user.Mail,
+			LoginName:         claims.UserLoginName(user.UserPrincipalName),
+			UserPrincipalName: user.UserPrincipalName,
+			PrincipalType:     client.User,
+		}
+
+		return grantHelper(ctx, securityPrincipal, sharingLinkRecipientEntitlement, rsc)
+	case recipient.Group != nil && recipient.Group.ID != "":
+		principal := &v2.ResourceId{
+			ResourceType: resourceTypeGroup,
+			Resource:     recipient.Group.ID,
+		}
+		return grant.NewGrant(rsc, sharingLinkRecipientEntitlement, principal, grant.WithAnnotation(
+			&v2.ExternalResourceMatchID{
+				Id: recipient.Group.ID,
+			},
+			&v2.GrantExpandable{
+				EntitlementIds: []string{entitlement.NewEntitlementID(&v2.Resource{Id: principal}, entraGroupMembersEntitlement)},
+			},
+		)), true, nil
+	case recipient.Application != nil && recipient.Application.ID != "":
+		principal := &v2.ResourceId{
+			ResourceType: appPrincipalResourceType.Id,
+			Resource:     recipient.Application.ID,
+		}
+		return grant.NewGrant(rsc, sharingLinkRecipientEntitlement, principal), true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// findRecipient looks for the principal among the recipients of a sharing link.
+func (s *sharingLinkBuilder) findRecipient(ctx context.Context, recipients []client.IdentitySet, principal *v2.Resource) (client.IdentitySet, bool, error) {
+	switch principal.Id.ResourceType {
+	case resourceTypeUser:
+		// users are keyed by object ID on Entra and by user principal name
+		// in grants, see recipientGrant, Microsoft Graph knows them by both
+		user, err := s.client.GetUserByID(ctx, principal.Id.Resource)
+		if err != nil {
+			return client.IdentitySet{}, false, fmt.Errorf("cannot find user '%s', error: %w", principal.Id.Resource, err)
+		}
+		loginName := claims.UserLoginName(user.UserPrincipalName)
+
+		for _, recipient := range recipients {
+			if recipient.User != nil && strings.EqualFold(recipient.User.ID, user.ID) {
+				return recipient, true, nil
+			}
+			if recipient.SiteUser != nil && user.UserPrincipalName != "" &&
+				isSameSecurityPrincipal(client.SecurityPrincipal{LoginName: recipient.SiteUser.LoginName}, loginName) {
+				return recipient, true, nil
+			}
+		}
+	case resourceTypeGroup:
+		for _, recipient := range recipients {
+			if recipient.Group != nil && strings.EqualFold(recipient.Group.ID, principal.Id.Resource) {
+				return recipient, true, nil
+			}
+		}
+	case siteUserResourceType.Id:
+		for _, recipient := range recipients {
+			if recipient.SiteUser != nil && strings.EqualFold(recipient.SiteUser.LoginName, principal.Id.Resource) {
+				return recipient, true, nil
+			}
+		}
+	}
+
+	return client.IdentitySet{}, false, nil
+}
+
+// driveRecipientOf identifies the recipient of a sharing link the way
+// Microsoft Graph expects it when revoking access.
+func driveRecipientOf(principal *v2.Resource, recipient client.IdentitySet) (client.DriveRecipient, error) {
+	switch {
+	case recipient.User != nil:
+		return client.DriveRecipient{ObjectID: recipient.User.ID}, nil
+	case recipient.Group != nil:
+		return client.DriveRecipient{ObjectID: recipient.Group.ID}, nil
+	case recipient.SiteUser != nil && recipient.SiteUser.Email != "":
+		return client.DriveRecipient{Email: recipient.SiteUser.Email}, nil
+	default:
+		return client.DriveRecipient{}, fmt.Errorf("recipient '%s' cannot be identified by Microsoft Graph", principal.Id.Resource)
+	}
+}
+
+// creatorOf returns who made the sharing link, when Microsoft Graph reports it.
+func creatorOf(permission client.DrivePermission) string {
+	if permission.Invitation == nil || permission.Invitation.InvitedBy == nil || permission.Invitation.InvitedBy.User == nil {
+		return ""
+	}
+
+	invitedBy := permission.Invitation.InvitedBy.User
+	if invitedBy.Email != "" {
+		return invitedBy.Email
+	}
+
+	return invitedBy.DisplayName
+}
+
+func convertSharingLink2Resource(siteID *v2.ResourceId, siteWebURL string, driveItem *client.DriveItem, permission client.DrivePermission) (*v2.Resource, error) {
+	link := permission.Link
+	isAnyoneLink := link.Scope == sharingLinkScopeAnonymous
+
+	roles := make([]any, 0, len(permission.Roles))
+	for _, role := range permission.Roles {
+		roles = append(roles, role)
+	}
+
+	profile := map[string]any{
+		"link type":         link.Type,
+		"link scope":        link.Scope,
+		"is anyone link":    isAnyoneLink,
+		"expiration":        permission.ExpirationDateTime,
+		"creator":           creatorOf(permission),
+		"url":               link.WebUrl,
+		"has password":      permission.HasPassword,
+		"prevents download": link.PreventsDownload,
+		"roles":             roles,
+		"item name":         driveItem.Name,
+		"item url":          driveItem.WebUrl,
+		"site url":          siteWebURL,
+	}
+
+	scopeLabel, ok := sharingLinkScopeLabels[link.Scope]
+	if !ok {
+		scopeLabel = link.Scope
+	}
+	displayName := fmt.Sprintf("%s %s link to %s", scopeLabel, link.Type, driveItem.Name)
+
+	description := fmt.Sprintf("%s link to %s", scopeLabel, driveItem.WebUrl)
+	if isAnyoneLink {
+		description = fmt.Sprintf("ANYONE LINK: anyone with the link can %s %s without signing in", link.Type, driveItem.WebUrl)
+	}
+
+	id := sharingLinkResourceID(siteID.Resource, driveItem.ParentReference.DriveID, driveItem.ID, permission.ID)
+	rsc, err := resource.NewGroupResource(displayName, sharingLinkResourceType, id,
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(siteID),
+		resource.WithDescription(description),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot make resource from sharing link of '%s', error: %w", driveItem.WebUrl, err)
+	}
+
+	return rsc, nil
+}
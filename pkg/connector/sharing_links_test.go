@@ -0,0 +1,260 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+const (
+	testDriveID        = "b!kB4GEh5c0EaZ1lXp1hO4tLmBs8TStmJNjWm2JZ5bYqg"
+	testDriveItemID    = "01BYE5RZ6QN3ZWBTUFOFD3GSPGOHDJD36K"
+	testPermissionID   = "aTowIy5mfG1lbWJlcnNoaXB8am9obkBjb250b3NvLmNvbQ"
+	testEntraUserID    = "6e7b768e-07e2-4810-8459-485f84f8f204"
+	testEntraGroupID   = "02bd9fd6-8f93-4758-87c3-1fb73740a315"
+	testPermissionsURL = "https://graph.microsoft.com/v1.0/drives/" + testDriveID + "/items/" + testDriveItemID + "/permissions/" + testPermissionID
+)
+
+var testSharingLink = &v2.Resource{
+	Id: &v2.ResourceId{
+		ResourceType: sharingLinkResourceType.Id,
+		Resource:     sharingLinkResourceID(testSiteID, testDriveID, testDriveItemID, testPermissionID),
+	},
+	DisplayName: "Specific people edit link to budget.xlsx",
+}
+
+// onEntraUser registers the Entra user id, by object ID and by user principal name.
+func (f *fakeTenant) onEntraUser(id, userPrincipalName string) {
+	user := client.EntraUser{ID: id, DisplayName: userPrincipalName, UserPrincipalName: userPrincipalName}
+	f.on(http.MethodGet, "https://graph.microsoft.com/v1.0/users/"+id, http.StatusOK, user)
+	f.on(http.MethodGet, "https://graph.microsoft.com/v1.0/users/"+userPrincipalName, http.StatusOK, user)
+}
+
+func TestSharingLinkBuilderList(t *testing.T) {
+	const (
+		uniqueID = "3f2a1b0c-9d8e-4f7a-b6c5-d4e3f2a1b0c9"
+		listID   = "7c6b5a49-3827-4165-9e8d-7c6b5a493827"
+	)
+
+	subsiteID := "contoso.sharepoint.com,8f7a9b5c-1234-4d5e-9f00-0a1b2c3d4e5f," + testWebID
+
+	testCases := []struct {
+		name        string
+		webURL      string
+		getter      string
+		graphSiteID string
+	}{
+		{
+			name:        "root web",
+			webURL:      testSiteWebURL,
+			getter:      "GetFileById",
+			graphSiteID: testSiteID,
+		},
+		{
+			// the groups are the ones of the site collection, the item is
+			// looked up in its subsites
+			name:        "subsite",
+			webURL:      testWebURL,
+			getter:      "GetFolderById",
+			graphSiteID: subsiteID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenant := newFakeTenant()
+			tenant.onSite(testSiteID, testSiteWebURL)
+			tenant.on(http.MethodGet, testSiteWebURL+"/_api/site", http.StatusOK, client.SharePointSite{Url: testSiteWebURL})
+			tenant.on(http.MethodGet, testSiteWebURL+"/_api/web/sitegroups", http.StatusOK, map[string]any{"value": []client.SharePointSiteGroup{
+				{Id: 7, Title: "SharingLinks." + uniqueID + ".Flexible.0b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d4e"},
+			}})
+			tenant.on(http.MethodGet, testSiteWebURL+"/_api/web/webs", http.StatusOK, map[string]any{"value": []client.Web{{Id: testWebID, Url: testWebURL}}})
+			tenant.on(http.MethodGet, testWebURL+"/_api/web/webs", http.StatusOK, map[string]any{"value": []client.Web{}})
+			tenant.on(http.MethodGet, tc.webURL+"/_api/web/"+tc.getter+"('"+uniqueID+"')/ListItemAllFields", http.StatusOK, map[string]any{
+				"Id":         12,
+				"ParentList": map[string]string{"Id": listID},
+			})
+			tenant.on(http.MethodGet, "https://graph.microsoft.com/v1.0/sites/"+tc.graphSiteID+"/lists/"+listID+"/items/12/driveItem", http.StatusOK, client.DriveItem{
+				ID:              testDriveItemID,
+				Name:            "budget.xlsx",
+				ParentReference: client.ItemReference{DriveID: testDriveID},
+			})
+			tenant.on(http.MethodGet, "https://graph.microsoft.com/v1.0/drives/"+testDriveID+"/items/"+testDriveItemID+"/permissions", http.StatusOK, map[string]any{"value": []client.DrivePermission{
+				{ID: testPermissionID, Roles: []string{"write"}, Link: &client.SharingLink{Type: "edit", Scope: "users"}},
+				// not a sharing link
+				{ID: "owner", Roles: []string{"owner"}},
+			}})
+
+			builder := newSharingLinkBuilder(newTestClient(t, tenant))
+
+			resources, npt, _, err := builder.List(context.Background(), &v2.ResourceId{ResourceType: siteResourceType.Id, Resource: testSiteID}, &pagination.Token{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if npt != "" {
+				t.Errorf("got next page token %q, want none", npt)
+			}
+
+			// the links of subsites are children of their site collection
+			want := []string{testSharingLink.Id.Resource}
+			if got := resourceIDs(resources); !slices.Equal(got, want) {
+				t.Errorf("got sharing links %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSharingLinkBuilderGrants(t *testing.T) {
+	tenant := newFakeTenant()
+	tenant.onEntraUser(testEntraUserID, "mary@contoso.com")
+	tenant.on(http.MethodGet, testPermissionsURL, http.StatusOK, client.DrivePermission{
+		ID:    testPermissionID,
+		Roles: []string{"write"},
+		GrantedToIdentitiesV2: []client.IdentitySet{
+			{SiteUser: &client.Identity{DisplayName: testEntraUser.Title, LoginName: testEntraUser.LoginName}},
+			// only the object ID of some users is reported
+			{User: &client.Identity{ID: testEntraUserID, DisplayName: "Mary Major"}},
+			{SiteUser: &client.Identity{DisplayName: testGuestUser.Title, Email: testGuestUser.Email, LoginName: testGuestUser.LoginName}},
+			{Group: &client.Identity{ID: testEntraGroupID, DisplayName: "Finance"}},
+			// skipped, the rest of the recipients are granted
+			{SiteUser: &client.Identity{DisplayName: "Malformed", LoginName: "i:0#.f|membership"}},
+		},
+	})
+
+	builder := newSharingLinkBuilder(newTestClient(t, tenant))
+
+	grants, npt, _, err := builder.Grants(context.Background(), testSharingLink, &pagination.Token{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if npt != "" {
+		t.Errorf("got next page token %q, want none", npt)
+	}
+
+	var got []string
+	for _, g := range grants {
+		got = append(got, g.Principal.Id.ResourceType+":"+g.Principal.Id.Resource)
+	}
+
+	// Entra users are keyed by user principal name, however they are reported
+	want := []string{
+		resourceTypeUser + ":john@contoso.com",
+		resourceTypeUser + ":mary@contoso.com",
+		siteUserResourceType.Id + ":" + testGuestUser.LoginName,
+		resourceTypeGroup + ":" + testEntraGroupID,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got principals %v, want %v", got, want)
+	}
+}
+
+func TestOffsetPage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		pageToken string
+		n         int
+		start     int
+		end       int
+		next      string
+	}{
+		{name: "first page", pageToken: "", n: 25, start: 0, end: 10, next: "10"},
+		{name: "middle page", pageToken: "10", n: 25, start: 10, end: 20, next: "20"},
+		{name: "last page", pageToken: "20", n: 25, start: 20, end: 25, next: ""},
+		{name: "single page", pageToken: "", n: 3, start: 0, end: 3, next: ""},
+		{name: "no element", pageToken: "", n: 0, start: 0, end: 0, next: ""},
+		{name: "fewer elements than the offset", pageToken: "20", n: 5, start: 5, end: 5, next: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, next, err := offsetPage(tc.pageToken, tc.n, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start != tc.start || end != tc.end || next != tc.next {
+				t.Errorf("got [%d:%d] and next %q, want [%d:%d] and next %q", start, end, next, tc.start, tc.end, tc.next)
+			}
+		})
+	}
+
+	for _, pageToken := range []string{"next", "-1"} {
+		if _, _, _, err := offsetPage(pageToken, 25, 10); err == nil {
+			t.Errorf("expected an error for page token '%s'", pageToken)
+		}
+	}
+}
+
+func TestSharingLinkBuilderRevoke(t *testing.T) {
+	const maryLoginName = "i:0#.f|membership|mary@contoso.com"
+
+	revokeURL := testPermissionsURL + "/revokeGrants"
+	recipientEntitlement := &v2.Entitlement{
+		Id:       testSharingLink.Id.Resource + ":" + sharingLinkRecipientEntitlement,
+		Resource: testSharingLink,
+		Slug:     sharingLinkRecipientEntitlement,
+	}
+
+	testCases := []struct {
+		name        string
+		principal   *v2.ResourceId
+		recipients  []client.IdentitySet
+		wantRevoked bool
+	}{
+		{
+			// users synced from Entra are keyed by object ID
+			name:        "Entra user by object ID",
+			principal:   &v2.ResourceId{ResourceType: resourceTypeUser, Resource: testEntraUserID},
+			recipients:  []client.IdentitySet{{SiteUser: &client.Identity{Email: "mary@contoso.com", LoginName: maryLoginName}}},
+			wantRevoked: true,
+		},
+		{
+			name:        "Entra user by user principal name",
+			principal:   &v2.ResourceId{ResourceType: resourceTypeUser, Resource: "mary@contoso.com"},
+			recipients:  []client.IdentitySet{{User: &client.Identity{ID: testEntraUserID}}},
+			wantRevoked: true,
+		},
+		{
+			name:        "site user",
+			principal:   &v2.ResourceId{ResourceType: siteUserResourceType.Id, Resource: testGuestUser.LoginName},
+			recipients:  []client.IdentitySet{{SiteUser: &client.Identity{Email: testGuestUser.Email, LoginName: testGuestUser.LoginName}}},
+			wantRevoked: true,
+		},
+		{
+			name:        "not a recipient",
+			principal:   &v2.ResourceId{ResourceType: resourceTypeUser, Resource: testEntraUserID},
+			recipients:  []client.IdentitySet{{SiteUser: &client.Identity{LoginName: testEntraUser.LoginName}}},
+			wantRevoked: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tenant := newFakeTenant()
+			tenant.onEntraUser(testEntraUserID, "mary@contoso.com")
+			tenant.on(http.MethodGet, testPermissionsURL, http.StatusOK, client.DrivePermission{ID: testPermissionID, GrantedToIdentitiesV2: tc.recipients})
+			tenant.on(http.MethodPost, revokeURL, http.StatusOK, client.DrivePermission{ID: testPermissionID})
+
+			builder := newSharingLinkBuilder(newTestClient(t, tenant))
+
+			annos, err := builder.Revoke(context.Background(), &v2.Grant{
+				Entitlement: recipientEntitlement,
+				Principal:   &v2.Resource{Id: tc.principal},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			alreadyRevoked := annos.Contains(&v2.GrantAlreadyRevoked{})
+			revoked := tenant.requested(http.MethodPost, revokeURL) == 1
+			if revoked != tc.wantRevoked || alreadyRevoked == tc.wantRevoked {
+				t.Errorf("got revoked %t and already revoked %t, want revoked %t", revoked, alreadyRevoked, tc.wantRevoked)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// Sites.Selected lets apps be granted a role (read, write, manage or
+// fullcontrol) on individual sites through Microsoft Graph, those
+// permissions don't show up among the role assignments of the site.
+
+const siteAppPermissionEntitlementPrefix = "app:"
+
+// siteAppPermissionEntitlementSlug makes the slug of the entitlement that
+// represents a Sites.Selected role.
+func siteAppPermissionEntitlementSlug(role string) string {
+	return siteAppPermissionEntitlementPrefix + role
+}
+
+// siteAppPermissionRoleOf returns the Sites.Selected role of the entitlement, if any.
+func siteAppPermissionRoleOf(slug string) (string, bool) {
+	role, found := strings.CutPrefix(slug, siteAppPermissionEntitlementPrefix)
+	if !found || !slices.Contains(client.SitePermissionRoles, role) {
+		return "", false
+	}
+
+	return role, true
+}
+
+func siteAppPermissionEntitlements(rsc *v2.Resource) []*v2.Entitlement {
+	ret := make([]*v2.Entitlement, 0, len(client.SitePermissionRoles))
+	for _, role := range client.SitePermissionRoles {
+		ret = append(ret, entitlement.NewPermissionEntitlement(rsc, siteAppPermissionEntitlementSlug(role),
+			entitlement.WithDisplayName(fmt.Sprintf("Sites.Selected %s on %s", role, rsc.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Apps granted the '%s' role on the site through Sites.Selected", role)),
+			entitlement.WithGrantableTo(appPrincipalResourceType),
+		))
+	}
+
+	return ret
+}
+
+// listSitePermissions lists the Sites.Selected permissions of the site,
+// along with their roles.
+func listSitePermissions(ctx context.Context, c *client.Client, siteID string) ([]client.SitePermission, error) {
+	permissions, err := c.ListSitePermissions(ctx, siteID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, permission := range permissions {
+		if len(permission.Roles) != 0 {
+			continue
+		}
+
+		// roles may be left out when listing
+		full, err := c.GetSitePermission(ctx, siteID, permission.ID)
+		if err != nil {
+			return nil, err
+		}
+		permissions[i] = *full
+	}
+
+	return permissions, nil
+}
+
+func siteAppPermissionGrants(rsc *v2.Resource, permissions []client.SitePermission) []*v2.Grant {
+	var ret []*v2.Grant
+	for _, permission := range permissions {
+		for _, role := range permission.Roles {
+			if !slices.Contains(client.SitePermissionRoles, role) { // i.e. "owner"
+				continue
+			}
+
+			for _, app := range permission.Applications() {
+				principal := &v2.ResourceId{
+					ResourceType: appPrincipalResourceType.Id,
+					Resource:     app.ID,
+				}
+				ret = append(ret, grant.NewGrant(rsc, siteAppPermissionEntitlementSlug(role), principal))
+			}
+		}
+	}
+
+	return ret
+}
+
+// findSitePermissionOfApp looks for the Sites.Selected permission granted to the app.
+func findSitePermissionOfApp(permissions []client.SitePermission, appID string) (client.SitePermission, bool) {
+	for _, permission := range permissions {
+		for _, app := range permission.Applications() {
+			if strings.EqualFold(app.ID, appID) {
+				return permission, true
+			}
+		}
+	}
+
+	return client.SitePermission{}, false
+}
+
+func grantSiteAppPermission(ctx context.Context, c *client.Client, principal *v2.Resource, ent *v2.Entitlement, role string) ([]*v2.Grant, annotations.Annotations, error) {
+	if principal.Id.ResourceType != appPrincipalResourceType.Id {
+		return nil, nil, fmt.Errorf("only app principals can be granted '%s', got '%s'", ent.Id, principal.Id.ResourceType)
+	}
+
+	siteID, err := siteGraphIDOf(ctx, c, ent.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions, err := listSitePermissions(ctx, c, siteID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot list Sites.Selected permissions, error: %w", err)
+	}
+
+	appID := principal.Id.Resource
+	permission, found := findSitePermissionOfApp(permissions, appID)
+	switch {
+	case found && slices.Contains(permission.Roles, role):
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	case found:
+		err = c.UpdateSitePermissionRoles(ctx, siteID, permission.ID, append(slices.Clone(permission.Roles), role))
+	default:
+		_, err = c.CreateSitePermission(ctx, siteID, role, appID, principal.DisplayName)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot grant '%s' to app '%s', error: %w", role, appID, err)
+	}
+
+	return []*v2.Grant{grant.NewGrant(ent.Resource, ent.Slug, principal.Id)}, nil, nil
+}
+
+func revokeSiteAppPermission(ctx context.Context, c *client.Client, toRevoke *v2.Grant, role string) (annotations.Annotations, error) {
+	siteID, err := siteGraphIDOf(ctx, c, toRevoke.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := listSitePermissions(ctx, c, siteID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list Sites.Selected permissions, error: %w", err)
+	}
+
+	appID := toRevoke.Principal.Id.Resource
+	permission, found := findSitePermissionOfApp(permissions, appID)
+	if !found || !slices.Contains(permission.Roles, role) {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	remaining := slices.DeleteFunc(slices.Clone(permission.Roles), func(r string) bool { return r == role })
+	if len(remaining) == 0 {
+		err = c.DeleteSitePermission(ctx, siteID, permission.ID)
+	} else {
+		err = c.UpdateSitePermissionRoles(ctx, siteID, permission.ID, remaining)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot revoke '%s' from app '%s', error: %w", role, appID, err)
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,130 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// siteUserBuilder syncs the users that only exist in SharePoint, like
+// share-by-email guests or legacy Windows claims. Entra users are left
+// out, they are matched against the Entra connector instead.
+//
+// Site users are listed per site; a user with access to many sites is
+// listed on each of them under the same ID and stored once.
+type siteUserBuilder struct {
+	client *client.Client
+}
+
+func (s *siteUserBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return siteUserResourceType
+}
+
+func (s *siteUserBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// site users are listed per site
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, s.client, parentResourceID.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	users, err := s.client.ListSecurityPrincipals(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("unable to list SharePoint security principals of site '%s', error: %w", siteWebURL, err)
+	}
+
+	var ret []*v2.Resource
+	for _, user := range users {
+		identity, err := claims.Parse(user.LoginName)
+		if err != nil {
+			ctxzap.Extract(ctx).Warn("cannot identify SharePoint user, skipping", zap.String("site", siteWebURL), zap.String("principal", user.LoginName), zap.Error(err))
+			continue
+		}
+		if !isSiteUser(user, identity) {
+			continue
+		}
+
+		rsc, err := convertSiteUser2Resource(user)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		ret = append(ret, rsc)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (s *siteUserBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (s *siteUserBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newSiteUserBuilder(c *client.Client) *siteUserBuilder {
+	return &siteUserBuilder{client: c}
+}
+
+// isSiteUser tells if the security principal is a user that has to be
+// synced as a site_user, that is, a user SharePoint knows but Entra doesn't.
+func isSiteUser(securityPrincipal client.SecurityPrincipal, identity claims.Identity) bool {
+	if securityPrincipal.PrincipalType != client.User {
+		return false
+	}
+
+	switch identity.Kind {
+	case claims.User:
+		return !isEntraUser(securityPrincipal, identity)
+	case claims.ExternalUser, claims.WindowsUser, claims.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+func convertSiteUser2Resource(user client.SecurityPrincipal) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"id":                                 user.Id,
+		"title":                              user.Title,
+		"email":                              user.Email,
+		"login name":                         user.LoginName,
+		"user principal name":                user.UserPrincipalName,
+		"is email authentication guest user": user.IsEmailAuthenticationGuestUser,
+		"is share by email guest user":       user.IsShareByEmailGuestUser,
+		"is hidden in ui":                    user.IsHiddenInUI,
+	}
+
+	opts := []resource.UserTraitOption{
+		resource.WithUserProfile(profile),
+		resource.WithUserLogin(user.LoginName),
+		resource.WithAccountType(v2.UserTrait_ACCOUNT_TYPE_HUMAN),
+		resource.WithStatus(v2.UserTrait_Status_STATUS_ENABLED),
+	}
+	if user.Email != "" {
+		opts = append(opts, resource.WithEmail(user.Email, true))
+	}
+
+	displayName := user.Title
+	if displayName == "" {
+		displayName = user.LoginName
+	}
+
+	rsc, err := resource.NewUserResource(displayName, siteUserResourceType, user.LoginName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create resource from SharePoint user '%s', error: %w", user.LoginName, err)
+	}
+
+	return rsc, nil
+}
@@ -0,0 +1,65 @@
+package connector
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+const (
+	testFinanceSiteID     = "contoso.sharepoint.com,11111111-1111-1111-1111-111111111111,22222222-2222-2222-2222-222222222222"
+	testFinanceSiteWebURL = "https://contoso.sharepoint.com/sites/finance"
+)
+
+var (
+	testEntraUser = client.SecurityPrincipal{
+		Id:                7,
+		Title:             "John Doe",
+		LoginName:         "i:0#.f|membership|john@contoso.com",
+		UserPrincipalName: "john@contoso.com",
+		PrincipalType:     client.User,
+	}
+	testGuestUser = client.SecurityPrincipal{
+		Id:                      8,
+		Title:                   "Jane Roe",
+		Email:                   "jane@fabrikam.com",
+		LoginName:               "i:0#.f|membership|urn%3aspo%3aguest#jane@fabrikam.com",
+		PrincipalType:           client.User,
+		IsShareByEmailGuestUser: true,
+	}
+	testWindowsUser = client.SecurityPrincipal{
+		Id:            9,
+		Title:         "Legacy Account",
+		LoginName:     `i:0#.w|contoso\legacy`,
+		PrincipalType: client.User,
+	}
+)
+
+// onSecurityPrincipals registers the security principals of the site.
+func (f *fakeTenant) onSecurityPrincipals(siteWebURL string, securityPrincipals ...client.SecurityPrincipal) {
+	f.on(http.MethodGet, siteWebURL+"/_api/web/siteusers", http.StatusOK, map[string]any{"value": securityPrincipals})
+}
+
+func TestSiteUserBuilderList(t *testing.T) {
+	tenant := newFakeTenant()
+	tenant.onSite(testSiteID, testSiteWebURL)
+	tenant.onSite(testFinanceSiteID, testFinanceSiteWebURL)
+	tenant.onSecurityPrincipals(testSiteWebURL, testEntraUser, testGuestUser)
+	// a malformed login name is skipped, the rest of the site still syncs
+	malformed := client.SecurityPrincipal{Id: 10, Title: "Malformed", LoginName: "i:0#.f|membership", PrincipalType: client.User}
+	tenant.onSecurityPrincipals(testFinanceSiteWebURL, testEntraUser, malformed, testGuestUser, testWindowsUser)
+
+	builder := newSiteUserBuilder(newTestClient(t, tenant))
+
+	// site users are keyed by login name, Entra users are left out
+	got := listTenantWide(t, builder, testSiteID, testFinanceSiteID)
+	want := [][]string{
+		{testGuestUser.LoginName},
+		{testGuestUser.LoginName, testWindowsUser.LoginName},
+	}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("got site users %v, want %v", got, want)
+	}
+}
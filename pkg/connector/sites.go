@@ -8,12 +8,18 @@ import (
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-sharepoint/pkg/client"
 )
 
+// siteAdminEntitlement is the slug of the site collection administrator entitlement.
+const siteAdminEntitlement = "admin"
+
 type siteBuilder struct {
 	client *client.Client
+	// sync the permissions granted to apps through Sites.Selected
+	syncSiteAppPermissions bool
 }
 
 func (o *siteBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
@@ -27,7 +33,7 @@ func (o *siteBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		return nil, "", nil, err
 	}
 
-	if bag.Current() != nil {
+	if bag.Current() == nil {
 		bag.Push(pagination.PageState{ResourceTypeID: siteResourceType.Id})
 	}
 
@@ -49,17 +55,39 @@ func (o *siteBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 	return ret, npt, nil, nil
 }
 
-func (o *siteBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
-	ent := entitlement.NewPermissionEntitlement(resource, "admin",
+func (o *siteBuilder) Entitlements(ctx context.Context, resource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	ent := entitlement.NewPermissionEntitlement(resource, siteAdminEntitlement,
 		entitlement.WithDisplayName(fmt.Sprintf("Administrator of %s", resource.DisplayName)),
-		entitlement.WithGrantableTo(securityPrincipalResourceType),
+		entitlement.WithGrantableTo(entraUserResourceType, securityPrincipalResourceType, siteUserResourceType, entraGroupResourceType),
 	)
+	ret := []*v2.Entitlement{ent}
+
+	siteWebURL, err := siteWebURLOf(ctx, o.client, resource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("siteBuilder.Entitlements: %w", err)
+	}
 
-	return []*v2.Entitlement{ent}, "", nil, nil
+	roleDefinitions, err := o.client.ListRoleDefinitions(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("siteBuilder.Entitlements: cannot list permission levels, error: %w", err)
+	}
+
+	ret = append(ret, roleDefinitionEntitlements(resource, roleDefinitions)...)
+
+	if o.syncSiteAppPermissions {
+		ret = append(ret, siteAppPermissionEntitlements(resource)...)
+	}
+
+	return ret, "", nil, nil
 }
 
 func (o *siteBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
-	users, err := o.client.ListSecurityPrincipals(ctx, rsc.Id.Resource)
+	siteWebURL, err := siteWebURLOf(ctx, o.client, rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("siteBuilder.Grants: %w", err)
+	}
+
+	users, err := o.client.ListSecurityPrincipals(ctx, siteWebURL)
 	if err != nil {
 		return nil, "", nil, fmt.Errorf("siteBuilder.Grants: cannot list users, error: %w", err)
 	}
@@ -70,7 +98,7 @@ func (o *siteBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pagi
 			continue
 		}
 
-		granted, isGrantable, err := grantHelper(ctx, user, "admin", rsc)
+		granted, isGrantable, err := grantHelper(ctx, user, siteAdminEntitlement, rsc)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("siteBuilder.Grants: failed to grant entitlement, error: %w", err)
 		}
@@ -80,11 +108,140 @@ func (o *siteBuilder) Grants(ctx context.Context, rsc *v2.Resource, pToken *pagi
 		ret = append(ret, granted)
 	}
 
-	return ret, "", nil, nil
+	assignments, err := o.client.ListRoleAssignments(ctx, siteWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("siteBuilder.Grants: cannot list role assignments, error: %w", err)
+	}
+
+	granted, err := roleAssignmentGrants(ctx, rsc.Id.Resource, rsc, assignments)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("siteBuilder.Grants: %w", err)
+	}
+	ret = append(ret, granted...)
+
+	if o.syncSiteAppPermissions {
+		siteID, err := siteGraphIDOf(ctx, o.client, rsc.Id.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("siteBuilder.Grants: %w", err)
+		}
+
+		permissions, err := listSitePermissions(ctx, o.client, siteID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("siteBuilder.Grants: cannot list Sites.Selected permissions, error: %w", err)
+		}
+		ret = append(ret, siteAppPermissionGrants(rsc, permissions)...)
+	}
+
+	return mergeGrants(ret), "", nil, nil
 }
 
-func newSiteBuilder(c *client.Client) *siteBuilder {
-	return &siteBuilder{client: c}
+func (o *siteBuilder) Grant(ctx context.Context, principal *v2.Resource, ent *v2.Entitlement) ([]*v2.Grant, annotations.Annotations, error) {
+	if role, ok := siteAppPermissionRoleOf(ent.Slug); ok {
+		grants, annos, err := grantSiteAppPermission(ctx, o.client, principal, ent, role)
+		if err != nil {
+			return nil, nil, fmt.Errorf("siteBuilder.Grant: %w", err)
+		}
+		return grants, annos, nil
+	}
+
+	if roleDefinitionID, ok := roleDefinitionIDOf(ent.Slug); ok {
+		grants, annos, err := grantRoleDefinition(ctx, o.client, principal, ent, roleDefinitionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("siteBuilder.Grant: %w", err)
+		}
+		return grants, annos, nil
+	}
+
+	if ent.Slug != siteAdminEntitlement {
+		return nil, nil, fmt.Errorf("siteBuilder.Grant: entitlement '%s' cannot be granted", ent.Id)
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, o.client, ent.Resource.Id.Resource)
+	if err != nil {
+		return nil, nil, fmt.Errorf("siteBuilder.Grant: %w", err)
+	}
+
+	loginName, err := loginNameForPrincipal(ctx, o.client, principal)
+	if err != nil {
+		return nil, nil, fmt.Errorf("siteBuilder.Grant: %w", err)
+	}
+
+	users, err := o.client.ListSecurityPrincipals(ctx, siteWebURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("siteBuilder.Grant: cannot list users, error: %w", err)
+	}
+
+	user, found := findSecurityPrincipal(users, loginName)
+	if found && user.IsSiteAdmin {
+		return nil, annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+	if !found { // the user must be part of the site collection first
+		ensured, err := o.client.EnsureUser(ctx, siteWebURL, loginName)
+		if err != nil {
+			return nil, nil, fmt.Errorf("siteBuilder.Grant: cannot add '%s' to site, error: %w", loginName, err)
+		}
+		user = *ensured
+	}
+
+	err = o.client.SetSiteAdmin(ctx, siteWebURL, user.Id, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("siteBuilder.Grant: cannot make '%s' site collection administrator, error: %w", loginName, err)
+	}
+
+	return []*v2.Grant{grant.NewGrant(ent.Resource, ent.Slug, principal.Id)}, nil, nil
+}
+
+func (o *siteBuilder) Revoke(ctx context.Context, toRevoke *v2.Grant) (annotations.Annotations, error) {
+	if role, ok := siteAppPermissionRoleOf(toRevoke.Entitlement.Slug); ok {
+		annos, err := revokeSiteAppPermission(ctx, o.client, toRevoke, role)
+		if err != nil {
+			return nil, fmt.Errorf("siteBuilder.Revoke: %w", err)
+		}
+		return annos, nil
+	}
+
+	if roleDefinitionID, ok := roleDefinitionIDOf(toRevoke.Entitlement.Slug); ok {
+		annos, err := revokeRoleDefinition(ctx, o.client, toRevoke, roleDefinitionID)
+		if err != nil {
+			return nil, fmt.Errorf("siteBuilder.Revoke: %w", err)
+		}
+		return annos, nil
+	}
+
+	if toRevoke.Entitlement.Slug != siteAdminEntitlement {
+		return nil, fmt.Errorf("siteBuilder.Revoke: entitlement '%s' cannot be revoked", toRevoke.Entitlement.Id)
+	}
+
+	siteWebURL, err := siteWebURLOf(ctx, o.client, toRevoke.Entitlement.Resource.Id.Resource)
+	if err != nil {
+		return nil, fmt.Errorf("siteBuilder.Revoke: %w", err)
+	}
+
+	loginName, err := loginNameForPrincipal(ctx, o.client, toRevoke.Principal)
+	if err != nil {
+		return nil, fmt.Errorf("siteBuilder.Revoke: %w", err)
+	}
+
+	users, err := o.client.ListSecurityPrincipals(ctx, siteWebURL)
+	if err != nil {
+		return nil, fmt.Errorf("siteBuilder.Revoke: cannot list users, error: %w", err)
+	}
+
+	user, found := findSecurityPrincipal(users, loginName)
+	if !found || !user.IsSiteAdmin {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	err = o.client.SetSiteAdmin(ctx, siteWebURL, user.Id, false)
+	if err != nil {
+		return nil, fmt.Errorf("siteBuilder.Revoke: cannot remove '%s' as site collection administrator, error: %w", loginName, err)
+	}
+
+	return nil, nil
+}
+
+func newSiteBuilder(c *client.Client, syncSiteAppPermissions bool) *siteBuilder {
+	return &siteBuilder{client: c, syncSiteAppPermissions: syncSiteAppPermissions}
 }
 
 func convertSite2Resource(site client.Site) (*v2.Resource, error) {
@@ -93,13 +250,30 @@ func convertSite2Resource(site client.Site) (*v2.Resource, error) {
 		"name":               site.Name,
 		"url":                site.WebUrl,
 		"microsoft graph ID": site.ID,
+		"legacy resource id": site.WebUrl,
+	}
+	// only multi-geo tenants have geo locations, i.e. "EUR"
+	if site.SiteCollection.DataLocationCode != "" {
+		profile["data location"] = site.SiteCollection.DataLocationCode
 	}
 
 	opts := []resource.GroupTraitOption{
 		resource.WithGroupProfile(profile),
 	}
 
-	rsc, err := resource.NewGroupResource(site.DisplayName, siteResourceType, site.WebUrl, opts)
+	rsc, err := resource.NewGroupResource(site.DisplayName, siteResourceType, site.ID, opts,
+		resource.WithAnnotation(
+			&v2.ChildResourceType{ResourceTypeId: groupResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: securityPrincipalResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: siteUserResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: appPrincipalResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: roleDefinitionResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: webResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: listResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: folderResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: sharingLinkResourceType.Id},
+		),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("cannot make resource from Site '%s', error: %w", site.DisplayName, err)
 	}
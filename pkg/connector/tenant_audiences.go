@@ -0,0 +1,92 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/claims"
+)
+
+// tenantAudience is a claim SharePoint uses to share content with (almost)
+// everybody in the tenant at once.
+type tenantAudience struct {
+	id          string
+	displayName string
+	description string
+	// tells if the identity belongs to this audience
+	matches func(identity claims.Identity) bool
+}
+
+var tenantAudiences = []tenantAudience{
+	{
+		id:          "everyone",
+		displayName: "Everyone",
+		description: "Everybody in the tenant, external (guest) users included",
+		matches: func(identity claims.Identity) bool {
+			return identity.Kind == claims.Everyone
+		},
+	},
+	{
+		id:          "everyone_except_external_users",
+		displayName: "Everyone except external users",
+		description: "Everybody in the tenant except for external (guest) users",
+		matches: func(identity claims.Identity) bool {
+			return identity.Kind == claims.RoleManager && strings.HasPrefix(identity.Identifier, "spo-grid-all-users")
+		},
+	},
+}
+
+// tenantAudienceOf finds the tenant audience the identity belongs to.
+func tenantAudienceOf(identity claims.Identity) (tenantAudience, bool) {
+	for _, audience := range tenantAudiences {
+		if audience.matches(identity) {
+			return audience, true
+		}
+	}
+
+	return tenantAudience{}, false
+}
+
+type tenantAudienceBuilder struct{}
+
+func (t *tenantAudienceBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return tenantAudienceResourceType
+}
+
+func (t *tenantAudienceBuilder) List(_ context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if parentResourceID != nil {
+		return nil, "", nil, nil
+	}
+
+	var ret []*v2.Resource
+	for _, audience := range tenantAudiences {
+		rsc, err := resource.NewGroupResource(audience.displayName, tenantAudienceResourceType, audience.id, []resource.GroupTraitOption{
+			resource.WithGroupProfile(map[string]interface{}{
+				"description": audience.description,
+			}),
+		}, resource.WithDescription(audience.description))
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("cannot create resource from tenant audience, err: %w", err)
+		}
+		ret = append(ret, rsc)
+	}
+
+	return ret, "", nil, nil
+}
+
+func (t *tenantAudienceBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func (t *tenantAudienceBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+func newTenantAudienceBuilder() *tenantAudienceBuilder {
+	return &tenantAudienceBuilder{}
+}
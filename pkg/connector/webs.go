@@ -0,0 +1,166 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sharepoint/pkg/client"
+)
+
+// webBuilder syncs the webs (subsites) of every site collection, no matter
+// how deep they are they are children of their site collection. Only webs
+// that break permission inheritance have entitlements and grants, the
+// rest share the ones of their parent.
+type webBuilder struct {
+	client *client.Client
+}
+
+func (w *webBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return webResourceType
+}
+
+func (w *webBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	// webs are listed per site collection
+	if parentResourceID == nil || parentResourceID.ResourceType != siteResourceType.Id {
+		return nil, "", nil, nil
+	}
+
+	bag := &pagination.Bag{}
+	err := bag.Unmarshal(pToken.Token)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if bag.Current() == nil {
+		siteWebURL, err := siteWebURLOf(ctx, w.client, parentResourceID.Resource)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("webBuilder.List: %w", err)
+		}
+
+		// Microsoft Graph lists some subsites as sites, their webs are
+		// listed along with the ones of their site collection
+		ok, err := isSiteCollection(ctx, w.client, siteWebURL)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("webBuilder.List: %w", err)
+		}
+		if !ok {
+			return nil, "", nil, nil
+		}
+
+		bag.Push(pagination.PageState{ResourceTypeID: webResourceType.Id, ResourceID: siteWebURL})
+	}
+
+	// every page lists the subsites right below one web, which are then
+	// queued to list their own subsites
+	parentWebURL := bag.Pop().ResourceID
+
+	webs, err := w.client.ListWebs(ctx, parentWebURL)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.List: cannot list subsites of '%s', error: %w", parentWebURL, err)
+	}
+
+	var ret []*v2.Resource
+	for _, web := range webs {
+		rsc, err := convertWeb2Resource(parentResourceID, parentWebURL, web)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("webBuilder.List: %w", err)
+		}
+		ret = append(ret, rsc)
+
+		bag.Push(pagination.PageState{ResourceTypeID: webResourceType.Id, ResourceID: web.Url})
+	}
+
+	npt, err := bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return ret, npt, nil, nil
+}
+
+func (w *webBuilder) Entitlements(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	_, web, err := w.webOf(ctx, rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.Entitlements: %w", err)
+	}
+	if !web.HasUniqueRoleAssignments { // the permissions of the parent apply
+		return nil, "", nil, nil
+	}
+
+	roleDefinitions, err := w.client.ListRoleDefinitions(ctx, web.Url)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.Entitlements: cannot list permission levels, error: %w", err)
+	}
+
+	return roleDefinitionEntitlements(rsc, roleDefinitions), "", nil, nil
+}
+
+func (w *webBuilder) Grants(ctx context.Context, rsc *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	siteID, web, err := w.webOf(ctx, rsc.Id.Resource)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.Grants: %w", err)
+	}
+	if !web.HasUniqueRoleAssignments { // the permissions of the parent apply
+		return nil, "", nil, nil
+	}
+
+	assignments, err := w.client.ListRoleAssignments(ctx, web.Url)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.Grants: cannot list role assignments, error: %w", err)
+	}
+
+	ret, err := roleAssignmentGrants(ctx, siteID, rsc, assignments)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("webBuilder.Grants: %w", err)
+	}
+
+	return mergeGrants(ret), "", nil, nil
+}
+
+// webOf finds the web identified by the resource ID, along with the ID of its site collection.
+func (w *webBuilder) webOf(ctx context.Context, resourceID string) (string, *client.Web, error) {
+	siteID, webURL, err := webURLOf(ctx, w.client, resourceID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	web, err := w.client.GetWeb(ctx, webURL)
+	if err != nil {
+		return "", nil, fmt.Errorf("cannot get subsite '%s', error: %w", webURL, err)
+	}
+
+	return siteID, web, nil
+}
+
+func newWebBuilder(c *client.Client) *webBuilder {
+	return &webBuilder{client: c}
+}
+
+func convertWeb2Resource(siteID *v2.ResourceId, parentWebURL string, web client.Web) (*v2.Resource, error) {
+	profile := map[string]any{
+		"title":                       web.Title,
+		"url":                         web.Url,
+		"server relative url":         web.ServerRelativeUrl,
+		"id":                          web.Id,
+		"parent web url":              parentWebURL,
+		"has unique role assignments": web.HasUniqueRoleAssignments,
+	}
+
+	rsc, err := resource.NewGroupResource(web.Title, webResourceType, webResourceID(siteID.Resource, web.Id),
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(siteID),
+		resource.WithAnnotation(
+			&v2.ChildResourceType{ResourceTypeId: listResourceType.Id},
+			&v2.ChildResourceType{ResourceTypeId: folderResourceType.Id},
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot make resource from subsite '%s', error: %w", web.Url, err)
+	}
+
+	return rsc, nil
+}